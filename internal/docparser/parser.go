@@ -2,12 +2,56 @@ package docparser
 
 import (
 	"bufio"
+	"slices"
+	"strconv"
 	"strings"
 )
 
 type Docs struct {
 	Title       string
 	Description string
+	Params      []Param
+	Group       bool // Set by @Group, marks a package doc comment as menu group metadata
+	Icon        string
+	Order       int
+	Hidden      bool
+	Deprecated  bool
+	// DeprecationMessage is the optional text after @Deprecated
+	DeprecationMessage string
+	Confirm            *Confirm
+	Tags               []string
+	Requires           []Requirement
+}
+
+// Requirement is a kind=value pair declared by @Requires, e.g. env=AWS_PROFILE
+type Requirement struct {
+	Kind  string
+	Value string
+}
+
+// Confirm holds the settings of a @Confirm annotation
+type Confirm struct {
+	Message string
+	Phrase  string // Phrase the user has to type, empty for a yes/no confirmation
+}
+
+// Param holds the prompt settings declared by a @Param annotation
+type Param struct {
+	Name    string
+	Prompt  string
+	Default string
+	Regex   string
+	Secret  bool
+}
+
+// Param returns the @Param annotation for the given parameter name, if any
+func (d Docs) Param(name string) (Param, bool) {
+	for _, param := range d.Params {
+		if param.Name == name {
+			return param, true
+		}
+	}
+	return Param{}, false
 }
 
 func ParseAnnotations(docstring string) Docs {
@@ -48,6 +92,54 @@ func ParseAnnotations(docstring string) Docs {
 				}
 				value := strings.TrimSpace(parts[1])
 				description.WriteString(value)
+			case "Group":
+				docs.Group = true
+				// "@Group Name" is a shorthand for the title
+				if len(parts) == 2 && docs.Title == "" {
+					docs.Title = strings.TrimSpace(parts[1])
+				}
+			case "Icon":
+				if len(parts) != 2 {
+					continue
+				}
+				docs.Icon = strings.TrimSpace(parts[1])
+			case "Order":
+				if len(parts) != 2 {
+					continue
+				}
+				if order, err := strconv.Atoi(strings.TrimSpace(parts[1])); err == nil {
+					docs.Order = order
+				}
+			case "Tags":
+				if len(parts) != 2 {
+					continue
+				}
+				docs.Tags = parseTags(docs.Tags, parts[1])
+			case "Requires":
+				if len(parts) != 2 {
+					continue
+				}
+				docs.Requires = append(docs.Requires, parseRequires(parts[1])...)
+			case "Hidden":
+				docs.Hidden = true
+			case "Deprecated":
+				docs.Deprecated = true
+				if len(parts) == 2 {
+					docs.DeprecationMessage = strings.TrimSpace(parts[1])
+				}
+			case "Confirm":
+				value := ""
+				if len(parts) == 2 {
+					value = parts[1]
+				}
+				docs.Confirm = parseConfirm(value)
+			case "Param":
+				if len(parts) != 2 {
+					continue
+				}
+				if param, ok := parseParam(parts[1]); ok {
+					docs.Params = append(docs.Params, param)
+				}
 			}
 			continue
 		}
@@ -66,3 +158,145 @@ func ParseAnnotations(docstring string) Docs {
 
 	return docs
 }
+
+// parseParam parses the arguments of a @Param annotation:
+// name "prompt" default=value regex="^pattern$" secret
+func parseParam(value string) (Param, bool) {
+	tokens := tokenize(value)
+	if len(tokens) == 0 || tokens[0].quoted {
+		return Param{}, false
+	}
+
+	param := Param{Name: tokens[0].text}
+	for _, tok := range tokens[1:] {
+		key, val, isOption := strings.Cut(tok.text, "=")
+		if !tok.quoted && tok.text == "secret" {
+			param.Secret = true
+			continue
+		}
+		if tok.quoted || !isOption {
+			if param.Prompt == "" {
+				param.Prompt = tok.text
+			}
+			continue
+		}
+
+		switch key {
+		case "default":
+			param.Default = unquote(val)
+		case "regex":
+			param.Regex = unquote(val)
+		}
+	}
+
+	return param, true
+}
+
+// parseTags appends the comma separated list of a @Tags annotation to tags.
+// Tags are lowercased, a leading # is dropped and inner whitespace becomes a dash,
+// so "#Read Only" and "read-only" are the same tag.
+func parseTags(tags []string, value string) []string {
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+		if tag == "" || slices.Contains(tags, tag) {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// parseRequires parses the arguments of a @Requires annotation:
+// env=FOO env=BAR,BAZ
+func parseRequires(value string) []Requirement {
+	var requirements []Requirement
+	for _, tok := range tokenize(value) {
+		kind, values, _ := strings.Cut(tok.text, "=")
+		for _, v := range strings.Split(unquote(values), ",") {
+			requirements = append(requirements, Requirement{Kind: kind, Value: strings.TrimSpace(v)})
+		}
+	}
+	return requirements
+}
+
+// parseConfirm parses the arguments of a @Confirm annotation:
+// "message" type=phrase
+func parseConfirm(value string) *Confirm {
+	confirm := &Confirm{}
+	var message []string
+
+	for _, tok := range tokenize(value) {
+		key, val, isOption := strings.Cut(tok.text, "=")
+		if !tok.quoted && isOption && key == "type" {
+			confirm.Phrase = unquote(val)
+			continue
+		}
+		message = append(message, tok.text)
+	}
+
+	confirm.Message = strings.Join(message, " ")
+	return confirm
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits on whitespace while keeping double-quoted sections together.
+// Quotes are kept in key=value tokens so the value can be unquoted later.
+func tokenize(value string) []token {
+	var tokens []token
+	var current strings.Builder
+	inQuotes := false
+	started := false
+	startsQuoted := false
+
+	flush := func() {
+		if started {
+			text := current.String()
+			if startsQuoted {
+				text = unquote(text)
+			}
+			tokens = append(tokens, token{text: text, quoted: startsQuoted})
+		}
+		current.Reset()
+		started = false
+		startsQuoted = false
+	}
+
+	runes := []rune(value)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case inQuotes && r == '\\' && i+1 < len(runes) && runes[i+1] == '"':
+			current.WriteString(`\"`)
+			i++
+		case r == '"':
+			if !started {
+				startsQuoted = true
+			}
+			started = true
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case !inQuotes && (r == ' ' || r == '\t'):
+			flush()
+		default:
+			started = true
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// unquote strips surrounding double quotes and resolves escaped quotes.
+// Other backslashes are kept as-is so regular expressions survive unchanged.
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		value = value[1 : len(value)-1]
+	}
+	return strings.ReplaceAll(value, `\"`, `"`)
+}
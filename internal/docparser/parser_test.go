@@ -0,0 +1,158 @@
+package docparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAnnotations_Params(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []Param
+	}{
+		{
+			name: "No params",
+			doc:  "@Charm\n@Title Hello",
+			want: nil,
+		},
+		{
+			name: "Name only",
+			doc:  "@Param name",
+			want: []Param{{Name: "name"}},
+		},
+		{
+			name: "Prompt, default and regex",
+			doc:  `@Param name "Enter your name" default=John regex="^[a-z ]+$"`,
+			want: []Param{{Name: "name", Prompt: "Enter your name", Default: "John", Regex: "^[a-z ]+$"}},
+		},
+		{
+			name: "Quoted default with escaped quote",
+			doc:  `@Param greeting default="say \"hi\""`,
+			want: []Param{{Name: "greeting", Default: `say "hi"`}},
+		},
+		{
+			name: "Backslashes are kept",
+			doc:  `@Param count "Count" regex="^\d+$"`,
+			want: []Param{{Name: "count", Prompt: "Count", Regex: `^\d+$`}},
+		},
+		{
+			name: "Secret",
+			doc:  `@Param token "API token" secret`,
+			want: []Param{{Name: "token", Prompt: "API token", Secret: true}},
+		},
+		{
+			name: "Multiple params end the description",
+			doc:  "@Description\nSome text\n@Param a\n@Param b \"B\"",
+			want: []Param{{Name: "a"}, {Name: "b", Prompt: "B"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAnnotations(tt.doc).Params
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAnnotations().Params = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAnnotations_DescriptionStopsAtParam(t *testing.T) {
+	docs := ParseAnnotations("@Title Hi\n@Description\n# Header\nBody\n@Param name")
+	if docs.Title != "Hi" {
+		t.Errorf("Title = %q, want %q", docs.Title, "Hi")
+	}
+	if docs.Description != "# Header\nBody" {
+		t.Errorf("Description = %q, want %q", docs.Description, "# Header\nBody")
+	}
+}
+
+func TestParseAnnotations_Confirm(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want *Confirm
+	}{
+		{
+			name: "No confirmation",
+			doc:  "@Charm",
+			want: nil,
+		},
+		{
+			name: "Bare annotation",
+			doc:  "@Confirm",
+			want: &Confirm{},
+		},
+		{
+			name: "Quoted message",
+			doc:  `@Confirm "Drop the database?"`,
+			want: &Confirm{Message: "Drop the database?"},
+		},
+		{
+			name: "Message and phrase",
+			doc:  `@Confirm "This removes all backups" type=delete-backups`,
+			want: &Confirm{Message: "This removes all backups", Phrase: "delete-backups"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAnnotations(tt.doc).Confirm
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAnnotations().Confirm = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAnnotations_Tags(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{
+			name: "No tags",
+			doc:  "@Charm",
+			want: nil,
+		},
+		{
+			name: "Comma separated",
+			doc:  "@Tags prod, read-only,slow",
+			want: []string{"prod", "read-only", "slow"},
+		},
+		{
+			name: "Normalized",
+			doc:  "@Tags #Prod, Read Only, , prod",
+			want: []string{"prod", "read-only"},
+		},
+		{
+			name: "Repeated annotation",
+			doc:  "@Tags prod\n@Tags db, prod",
+			want: []string{"prod", "db"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAnnotations(tt.doc).Tags
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAnnotations().Tags = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAnnotations_Requires(t *testing.T) {
+	docs := ParseAnnotations("@Requires env=AWS_PROFILE env=REGION,ACCOUNT\n@Requires env=TOKEN")
+	want := []Requirement{
+		{Kind: "env", Value: "AWS_PROFILE"},
+		{Kind: "env", Value: "REGION"},
+		{Kind: "env", Value: "ACCOUNT"},
+		{Kind: "env", Value: "TOKEN"},
+	}
+	if !reflect.DeepEqual(docs.Requires, want) {
+		t.Errorf("ParseAnnotations().Requires = %+v, want %+v", docs.Requires, want)
+	}
+}
@@ -1,19 +1,29 @@
 package generator
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"github.com/ImGajeed76/charmer/internal/docparser"
 	"go/ast"
 	"go/doc"
-	"go/parser"
+	"go/format"
 	"go/token"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"text/template"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
 )
 
+// ErrStaleRegistry is returned in check mode when the generated registry is out of date
+var ErrStaleRegistry = errors.New("registry is stale")
+
 type CharmFunction struct {
 	Name        string
 	Doc         string
@@ -21,6 +31,13 @@ type CharmFunction struct {
 	Path        string
 	Title       string
 	Description string
+	Signature   Signature
+	Order       int
+	Hidden      bool
+	Deprecated  string
+	Confirm     *docparser.Confirm
+	Tags        []string
+	RequiredEnv []string
 }
 
 type Import struct {
@@ -28,124 +45,232 @@ type Import struct {
 	ParentPath string
 }
 
+type Group struct {
+	Path        string
+	Title       string
+	Description string
+	Icon        string
+	Order       int
+}
+
 type RegistryData struct {
-	Imports []Import
-	Charms  []CharmFunction
+	PackageName        string
+	VariableName       string
+	GroupsVariableName string
+	Imports            []Import
+	Charms             []CharmFunction
+	Groups             []Group
 }
 
 const registryTemplate = `// Code generated by charm-generator; DO NOT EDIT.
-package registry
+package {{.PackageName}}
 
 import (
 {{- range .Imports}}
     {{.Package}} "{{.ParentPath}}"
+{{- end}}
+{{- if .Charms}}
+    "context"
 {{- end}}
     "github.com/ImGajeed76/charmer/pkg/charmer/models"
 )
 
-var RegisteredCharms = map[string]models.CharmFunc{
+var {{.VariableName}} = map[string]models.CharmFunc{
 {{- range .Charms}}
-    "{{.Path}}": {
-        Name:    "{{.Name}}",
+    {{printf "%q" .Path}}: {
+        Name:    {{printf "%q" .Name}},
         Doc:     ` + "`{{.Doc}}`" + `,
-        Execute: {{.Package}}.{{.Name}},
-		Path:    "{{.Path}}",
-		Title:   "{{.Title}}",
-		Description: ` + "`{{.Description}}`" + `,
+{{- if .Signature.Params}}
+        Params: []models.CharmParam{
+{{- range .Signature.Params}}
+            {Name: {{printf "%q" .Name}}, Type: models.{{.Type}}, Prompt: {{printf "%q" .Prompt}}, Default: {{printf "%q" .Default}}, Regex: {{printf "%q" .Regex}}{{if .Secret}}, Secret: true{{end}}{{if .Options}}, Options: []string{ {{- range $i, $o := .Options}}{{if $i}}, {{end}}{{printf "%q" $o}}{{end -}} }{{end}}},
+{{- end}}
+        },
+{{- end}}
+        Execute: func(ctx context.Context, args []any) error {
+{{- if .Signature.ReturnsError}}
+            return {{.Package}}.{{.Name}}({{.Signature.CallArgs}})
+{{- else}}
+            {{.Package}}.{{.Name}}({{.Signature.CallArgs}})
+            return nil
+{{- end}}
+        },
+        Path:    {{printf "%q" .Path}},
+        Title:   {{printf "%q" .Title}},
+        Description: ` + "`{{.Description}}`" + `,
+{{- if .Order}}
+        Order: {{.Order}},
+{{- end}}
+{{- if .Hidden}}
+        Hidden: true,
+{{- end}}
+{{- if .Deprecated}}
+        Deprecated: {{printf "%q" .Deprecated}},
+{{- end}}
+{{- with .Confirm}}
+        Confirm: &models.Confirmation{Message: {{printf "%q" .Message}}, Phrase: {{printf "%q" .Phrase}}},
+{{- end}}
+{{- if .Tags}}
+        Tags: []string{ {{- range $i, $t := .Tags}}{{if $i}}, {{end}}{{printf "%q" $t}}{{end -}} },
+{{- end}}
+{{- if .RequiredEnv}}
+        RequiredEnv: []string{ {{- range $i, $e := .RequiredEnv}}{{if $i}}, {{end}}{{printf "%q" $e}}{{end -}} },
+{{- end}}
+    },
+{{- end}}
+}
+
+var {{.GroupsVariableName}} = map[string]models.CharmGroup{
+{{- range .Groups}}
+    {{printf "%q" .Path}}: {
+        Path:        {{printf "%q" .Path}},
+        Title:       {{printf "%q" .Title}},
+        Description: {{printf "%q" .Description}},
+        Icon:        {{printf "%q" .Icon}},
+        Order:       {{.Order}},
     },
 {{- end}}
 }
 `
 
+// FindCharms collects all charms below dir and the imports the registry needs for them
 func FindCharms(dir string, moduleRoot string) ([]CharmFunction, []Import, error) {
+	pkgs, err := loadCharmPackages(dir, moduleRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	return findCharms(pkgs, dir, moduleRoot)
+}
+
+// findCharms collects the charms from packages loaded by loadCharmPackages
+func findCharms(pkgs []*packages.Package, dir string, moduleRoot string) ([]CharmFunction, []Import, error) {
 	var charms []CharmFunction
 	importMap := make(map[string]Import)         // Using map to deduplicate
 	usedModulePaths := make(map[string]struct{}) // Using map to deduplicate
 
 	// Get module name from go.mod
-	moduleData, err := os.ReadFile(filepath.Join(moduleRoot, "go.mod"))
+	moduleName, err := readModulePath(moduleRoot)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading go.mod: %v", err)
+		return nil, nil, err
 	}
 
-	lines := strings.Split(string(moduleData), "\n")
-	if len(lines) == 0 {
-		return nil, nil, fmt.Errorf("empty go.mod file")
+	absRoot, err := filepath.Abs(moduleRoot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resolving module root: %v", err)
 	}
-
-	parts := strings.Fields(lines[0])
-	if len(parts) != 2 || parts[0] != "module" {
-		return nil, nil, fmt.Errorf("invalid go.mod format")
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resolving charms directory: %v", err)
 	}
-	moduleName := parts[1]
 
-	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	// Charms in test files are never compiled into the registry
+	err = filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return err
+		}
+		files, err := testFiles(path)
 		if err != nil {
 			return err
 		}
+		return checkExcludedFiles(files, "in a _test.go file")
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var charmPkgs []*packages.Package
+	for _, pkg := range pkgs {
+		if err := checkExcludedFiles(pkg.IgnoredFiles, "excluded by build constraints"); err != nil {
+			return nil, nil, err
+		}
 
-		// Skip directories in the walk
-		if info.IsDir() {
-			return nil
+		// Directories without buildable files (e.g. only build-tagged files) have nothing to register
+		if len(pkg.GoFiles) == 0 {
+			continue
+		}
+		if len(pkg.Errors) > 0 {
+			return nil, nil, fmt.Errorf("error loading package %s: %v", pkg.PkgPath, pkg.Errors[0])
+		}
+		if pkg.PkgPath != moduleName && !strings.HasPrefix(pkg.PkgPath, moduleName+"/") {
+			return nil, nil, fmt.Errorf("package %s is not part of module %s", pkg.PkgPath, moduleName)
 		}
 
-		// Only process .go files
-		if !strings.HasSuffix(path, ".go") {
-			return nil
+		if hasCharms(pkg) {
+			charmPkgs = append(charmPkgs, pkg)
 		}
+	}
 
-		// Create a new file set
-		fset := token.NewFileSet()
+	aliases, err := importAliases(charmPkgs, absRoot)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		// Parse the file (not directory)
-		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	for _, pkg := range charmPkgs {
+		alias := aliases[pkg.PkgPath]
+
+		// Create package doc from all files of the package
+		docPkg, err := doc.NewFromFiles(pkg.Fset, pkg.Syntax, pkg.PkgPath, doc.AllDecls|doc.PreserveAST)
 		if err != nil {
-			return fmt.Errorf("error parsing file %s: %v", path, err)
+			return nil, nil, fmt.Errorf("error reading package %s: %v", pkg.PkgPath, err)
 		}
 
-		// Create package doc
-		pkg := &ast.Package{
-			Name:  file.Name.Name,
-			Files: map[string]*ast.File{path: file},
+		// Get path relative to the charms directory
+		relPath, err := filepath.Rel(absDir, packageDir(pkg))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error getting relative path: %v", err)
+		}
+		relPath = filepath.ToSlash(relPath)
+		if relPath == "." {
+			relPath = ""
 		}
-		docPkg := doc.New(pkg, "./", 0)
 
-		// Look through all functions
-		for _, fn := range docPkg.Funcs {
-			if strings.Contains(fn.Doc, "@Charm") {
-				// Escape ` in docstring
-				fn.Doc = strings.ReplaceAll(fn.Doc, "`", "` + \"`\" + `")
-				// Escape \ in docstring
-				fn.Doc = strings.ReplaceAll(fn.Doc, "\\", "\\\\")
+		for _, file := range pkg.Syntax {
+			path := pkg.Fset.Position(file.Pos()).Filename
 
-				// Parse docstring for annotations
-				docs := docparser.ParseAnnotations(fn.Doc)
+			// Look through all functions
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || !isCharm(fn) {
+					continue
+				}
 
-				// Get the parent directory path
-				parentDir := filepath.Dir(path)
+				if fn.Recv != nil {
+					return nil, nil, fmt.Errorf("error in file %s: charm %s is a method, charms must be plain functions", path, fn.Name.Name)
+				}
+				if !fn.Name.IsExported() {
+					return nil, nil, fmt.Errorf("error in file %s: charm %s must be exported", path, fn.Name.Name)
+				}
+
+				fnDoc := fn.Doc.Text()
+
+				// Parameter annotations are parsed before escaping, their values are quoted by the template
+				rawDocs := docparser.ParseAnnotations(fnDoc)
+				signature, err := resolveSignature(fn, file, docPkg, alias, rawDocs)
+				if err != nil {
+					return nil, nil, fmt.Errorf("error in file %s: %v", path, err)
+				}
 
-				// Get path relative to project root
-				relPath, err := filepath.Rel(moduleRoot, parentDir)
+				requiredEnv, err := requiredEnv(rawDocs)
 				if err != nil {
-					return fmt.Errorf("error getting relative path: %v", err)
+					return nil, nil, fmt.Errorf("error in file %s: charm %s: %v", path, fn.Name.Name, err)
 				}
 
-				// Combine with module name
-				fullPath := filepath.Join(moduleName, relPath)
-				// Convert to forward slashes for Go import path
-				fullPath = filepath.ToSlash(fullPath)
+				// Splice ` into the raw string literals the template writes the docstring to
+				fnDoc = strings.ReplaceAll(fnDoc, "`", "` + \"`\" + `")
+
+				// Parse docstring for annotations
+				docs := docparser.ParseAnnotations(fnDoc)
 
 				// Get the module path
-				modulePath := strings.TrimPrefix(relPath, "charms")
-				modulePath = strings.TrimPrefix(modulePath, "/")
+				modulePath := relPath
 				if modulePath != "" {
 					modulePath += "/"
 				}
-				modulePath += strings.TrimSuffix(fn.Name, ".go")
+				modulePath += fn.Name.Name
 
 				// If module path is already used, give a helpful error
 				if _, ok := usedModulePaths[modulePath]; ok {
-					return fmt.Errorf("duplicate charm module path '%s' detected. Each charm must have a unique module path. This can happen if:\n"+
+					return nil, nil, fmt.Errorf("duplicate charm module path '%s' detected. Each charm must have a unique module path. This can happen if:\n"+
 						"1. You have multiple charm functions in different files but with the same directory structure and title\n"+
 						"2. You have copied a charm file without renaming its @Title annotation\n\n"+
 						"To fix this:\n"+
@@ -160,28 +285,32 @@ func FindCharms(dir string, moduleRoot string) ([]CharmFunction, []Import, error
 				usedModulePaths[modulePath] = struct{}{}
 
 				charm := CharmFunction{
-					Name:        fn.Name,
-					Doc:         strings.TrimSpace(fn.Doc),
-					Package:     file.Name.Name,
+					Name:        fn.Name.Name,
+					Doc:         strings.TrimSpace(fnDoc),
+					Package:     alias,
 					Path:        modulePath,
-					Title:       docs.Title,
+					Title:       rawDocs.Title,
 					Description: docs.Description,
+					Signature:   signature,
+					Order:       rawDocs.Order,
+					Hidden:      rawDocs.Hidden,
+					Deprecated:  deprecationMessage(rawDocs),
+					Confirm:     confirmation(rawDocs),
+					Tags:        rawDocs.Tags,
+					RequiredEnv: requiredEnv,
 				}
 				charms = append(charms, charm)
 
 				// Add to imports map
-				importMap[file.Name.Name] = Import{
-					Package:    file.Name.Name,
-					ParentPath: fullPath,
+				importMap[alias] = Import{
+					Package:    alias,
+					ParentPath: pkg.PkgPath,
+				}
+				for _, imp := range signature.Imports {
+					importMap[imp.Package] = imp
 				}
 			}
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, nil, err
 	}
 
 	// Convert import map to slice
@@ -190,58 +319,248 @@ func FindCharms(dir string, moduleRoot string) ([]CharmFunction, []Import, error
 		imports = append(imports, imp)
 	}
 
+	// Sort everything so the generated registry doesn't change between runs
+	sort.Slice(imports, func(i, j int) bool {
+		return imports[i].ParentPath < imports[j].ParentPath
+	})
+	sort.Slice(charms, func(i, j int) bool {
+		return charms[i].Path < charms[j].Path
+	})
+
 	return charms, imports, nil
 }
 
-func GenerateRegistry(charms []CharmFunction, imports []Import) error {
-
+// RenderRegistry executes the registry template and formats the result with gofmt
+func RenderRegistry(options Options, data RegistryData) ([]byte, error) {
 	// Prepare template data
-	data := RegistryData{
-		Imports: imports,
-		Charms:  charms,
+	data.PackageName = options.Package
+	data.VariableName = options.Variable
+	data.GroupsVariableName = options.GroupsVariable
+
+	// Parse and execute the template
+	tmpl, err := template.New("registry").Parse(registryTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("error executing template: %v", err)
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated code: %v", err)
+	}
+
+	return formatted, nil
+}
+
+func GenerateRegistry(options Options, data RegistryData) error {
+	content, err := RenderRegistry(options, data)
+	if err != nil {
+		return err
 	}
 
 	// Create output directory if it doesn't exist
-	err := os.MkdirAll("internal/registry", 0755)
+	err = os.MkdirAll(filepath.Dir(options.Output), 0755)
 	if err != nil {
 		return fmt.Errorf("error creating registry directory: %v", err)
 	}
 
-	// Create the output file
-	f, err := os.Create("internal/registry/generated.go")
+	err = os.WriteFile(options.Output, content, 0644)
 	if err != nil {
-		return fmt.Errorf("error creating generated file: %v", err)
+		return fmt.Errorf("error writing generated file: %v", err)
 	}
-	defer func(f *os.File) {
-		err := f.Close()
-		if err != nil {
-			log.Printf("error closing file: %v", err)
-		}
-	}(f)
 
-	// Parse and execute the template
-	tmpl, err := template.New("registry").Parse(registryTemplate)
+	return nil
+}
+
+// CheckRegistry returns ErrStaleRegistry if the registry on disk differs from the one that would be generated
+func CheckRegistry(options Options, data RegistryData) error {
+	content, err := RenderRegistry(options, data)
 	if err != nil {
-		return fmt.Errorf("error parsing template: %v", err)
+		return err
 	}
 
-	err = tmpl.Execute(f, data)
+	existing, err := os.ReadFile(options.Output)
 	if err != nil {
-		return fmt.Errorf("error executing template: %v", err)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s does not exist", ErrStaleRegistry, options.Output)
+		}
+		return fmt.Errorf("error reading generated file: %v", err)
+	}
+
+	if !bytes.Equal(existing, content) {
+		return fmt.Errorf("%w: %s is out of date, run go generate", ErrStaleRegistry, options.Output)
+	}
+
+	return nil
+}
+
+// Options configures Generate
+type Options struct {
+	Check      bool   // Only verify that the registry is up to date, without writing anything
+	ModuleRoot string // Directory containing go.mod
+	CharmsDir  string // Directory searched for charms, relative to the working directory
+	Output     string // Path of the generated registry file
+	Package    string // Package name of the generated registry
+	Variable   string // Name of the generated registry variable
+
+	GroupsVariable string // Name of the generated group metadata variable
+}
+
+// DefaultOptions returns the default options
+func DefaultOptions() Options {
+	return Options{
+		ModuleRoot: "./",
+		CharmsDir:  "charms",
+		Output:     "internal/registry/generated.go",
+		Package:    "registry",
+		Variable:   "RegisteredCharms",
+
+		GroupsVariable: "RegisteredGroups",
 	}
+}
 
+// validate checks that the options produce a valid registry file
+func (o Options) validate() error {
+	if !token.IsIdentifier(o.Package) {
+		return fmt.Errorf("invalid registry package name '%s'", o.Package)
+	}
+	if !token.IsIdentifier(o.Variable) {
+		return fmt.Errorf("invalid registry variable name '%s'", o.Variable)
+	}
+	if !token.IsIdentifier(o.GroupsVariable) || o.GroupsVariable == o.Variable {
+		return fmt.Errorf("invalid groups variable name '%s'", o.GroupsVariable)
+	}
+	if o.CharmsDir == "" || o.Output == "" {
+		return fmt.Errorf("charms directory and output path must not be empty")
+	}
 	return nil
 }
 
-func Generate() {
+// Generate finds all charms and writes the registry.
+// In check mode it returns ErrStaleRegistry instead if the registry is out of date.
+func Generate(options Options) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+
 	// if charms directory does not exist, create it with an example charm
-	if _, err := os.Stat("charms"); os.IsNotExist(err) {
-		err := os.Mkdir("charms", 0755)
+	if _, err := os.Stat(options.CharmsDir); os.IsNotExist(err) && !options.Check {
+		err := os.MkdirAll(options.CharmsDir, 0755)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 
-		err = os.WriteFile("charms/greeting.go", []byte(`package charms
+		err = os.WriteFile(filepath.Join(options.CharmsDir, "greeting.go"), []byte(fmt.Sprintf(exampleCharm, examplePackageName(options.CharmsDir))), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Loading the packages is the slow part, so charms and groups share them
+	pkgs, err := loadCharmPackages(options.CharmsDir, options.ModuleRoot)
+	if err != nil {
+		return err
+	}
+
+	charms, imports, err := findCharms(pkgs, options.CharmsDir, options.ModuleRoot)
+	if err != nil {
+		return err
+	}
+
+	groups, err := findGroups(pkgs, options.CharmsDir)
+	if err != nil {
+		return err
+	}
+
+	data := RegistryData{
+		Imports: imports,
+		Charms:  charms,
+		Groups:  groups,
+	}
+
+	if options.Check {
+		return CheckRegistry(options, data)
+	}
+
+	err = GenerateRegistry(options, data)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Generated %s with %d charms\n", options.Output, len(charms))
+	return nil
+}
+
+// deprecationMessage returns the message shown for a deprecated charm, or an empty string
+func deprecationMessage(docs docparser.Docs) string {
+	if !docs.Deprecated {
+		return ""
+	}
+	if docs.DeprecationMessage == "" {
+		return "This charm is deprecated."
+	}
+	return docs.DeprecationMessage
+}
+
+// confirmation returns the @Confirm settings with a default message, or nil
+func confirmation(docs docparser.Docs) *docparser.Confirm {
+	if docs.Confirm == nil {
+		return nil
+	}
+	confirm := *docs.Confirm
+	if confirm.Message == "" {
+		confirm.Message = "Are you sure you want to run this charm?"
+	}
+	return &confirm
+}
+
+// requiredEnv returns the environment variables declared by @Requires env=NAME
+func requiredEnv(docs docparser.Docs) ([]string, error) {
+	var env []string
+	for _, req := range docs.Requires {
+		if req.Kind != "env" {
+			return nil, fmt.Errorf("unsupported requirement '%s=%s' in @Requires, only env=NAME is supported", req.Kind, req.Value)
+		}
+		if req.Value == "" {
+			return nil, fmt.Errorf("missing environment variable name in @Requires env=")
+		}
+		if !slices.Contains(env, req.Value) {
+			env = append(env, req.Value)
+		}
+	}
+	return env, nil
+}
+
+// readModulePath reads the module path from the go.mod in moduleRoot
+func readModulePath(moduleRoot string) (string, error) {
+	goModPath := filepath.Join(moduleRoot, "go.mod")
+	moduleData, err := os.ReadFile(goModPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading go.mod: %v", err)
+	}
+
+	modulePath := modfile.ModulePath(moduleData)
+	if modulePath == "" {
+		return "", fmt.Errorf("no module declaration found in %s", goModPath)
+	}
+	return modulePath, nil
+}
+
+// examplePackageName derives the package name of the example charm from its directory
+func examplePackageName(dir string) string {
+	name := filepath.Base(filepath.Clean(dir))
+	if token.IsIdentifier(name) {
+		return name
+	}
+	return "charms"
+}
+
+const exampleCharm = `package %s
 
 import (
 	"fmt"
@@ -262,18 +581,4 @@ func Greeting() {
 
 	fmt.Println("Hello", name)
 }
-`), 0644)
-	}
-
-	charms, imports, err := FindCharms("charms", "./")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = GenerateRegistry(charms, imports)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	log.Printf("Generated registry.go with %d charms\n", len(charms))
-}
+`
@@ -0,0 +1,254 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeModule creates a temporary module with the given files and returns its root
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+
+	files["go.mod"] = "module example.com/app\n\ngo 1.24.0\n"
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestFindCharms_PackageNameCollision(t *testing.T) {
+	root := writeModule(t, map[string]string{
+		"charms/db/backup.go": "package charms\n\n// Backup godoc\n// @Charm\nfunc Backup() {}\n",
+		"charms/net/ping.go":  "package charms\n\n// Ping godoc\n// @Charm\nfunc Ping() {}\n",
+		"charms/misc/a.go":    "package misc\n\ntype Mode int\n\nconst (\n\tFast Mode = iota\n\tSlow\n)\n",
+		"charms/misc/b.go":    "package misc\n\n// Run godoc\n// @Charm\nfunc Run(mode Mode) {}\n",
+	})
+
+	charms, imports, err := FindCharms(filepath.Join(root, "charms"), root)
+	if err != nil {
+		t.Fatalf("FindCharms() error = %v", err)
+	}
+
+	wantImports := map[string]string{
+		"charms_db":  "example.com/app/charms/db",
+		"charms_net": "example.com/app/charms/net",
+		"misc":       "example.com/app/charms/misc",
+	}
+	if len(imports) != len(wantImports) {
+		t.Fatalf("FindCharms() imports = %+v, want %v", imports, wantImports)
+	}
+	for _, imp := range imports {
+		if wantImports[imp.Package] != imp.ParentPath {
+			t.Errorf("import %s %q, want %q", imp.Package, imp.ParentPath, wantImports[imp.Package])
+		}
+	}
+
+	wantPaths := []string{"db/Backup", "misc/Run", "net/Ping"}
+	if len(charms) != len(wantPaths) {
+		t.Fatalf("FindCharms() found %d charms, want %d", len(charms), len(wantPaths))
+	}
+	for i, charm := range charms {
+		if charm.Path != wantPaths[i] {
+			t.Errorf("charm %d path = %q, want %q", i, charm.Path, wantPaths[i])
+		}
+	}
+
+	// The enum type is declared in a different file of the same package
+	if got := charms[1].Signature.Params[0].Options; strings.Join(got, ",") != "Fast,Slow" {
+		t.Errorf("enum options = %v, want [Fast Slow]", got)
+	}
+}
+
+func TestFindCharms_InvalidCharms(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "Unexported",
+			file:    "charms/a.go",
+			content: "package charms\n\n// run godoc\n// @Charm\nfunc run() {}\n",
+			wantErr: "must be exported",
+		},
+		{
+			name:    "Method",
+			file:    "charms/a.go",
+			content: "package charms\n\ntype S struct{}\n\n// Run godoc\n// @Charm\nfunc (S) Run() {}\n",
+			wantErr: "is a method",
+		},
+		{
+			name:    "Test file",
+			file:    "charms/a_test.go",
+			content: "package charms\n\n// Run godoc\n// @Charm\nfunc Run() {}\n",
+			wantErr: "_test.go",
+		},
+		{
+			name:    "Build tags",
+			file:    "charms/a.go",
+			content: "//go:build ignore\n\npackage charms\n\n// Run godoc\n// @Charm\nfunc Run() {}\n",
+			wantErr: "build constraints",
+		},
+		{
+			name:    "Unsupported requirement",
+			file:    "charms/a.go",
+			content: "package charms\n\n// Run godoc\n// @Charm\n// @Requires cmd=kubectl\nfunc Run() {}\n",
+			wantErr: "only env=NAME is supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeModule(t, map[string]string{
+				"charms/ok.go": "package charms\n",
+				tt.file:        tt.content,
+			})
+
+			_, _, err := FindCharms(filepath.Join(root, "charms"), root)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FindCharms() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindGroups(t *testing.T) {
+	root := writeModule(t, map[string]string{
+		"charms/db/doc.go":     "// @Group\n// @Title Database\n// @Icon 🗄\n// @Order 2\n// @Description\n// # Database\npackage db\n",
+		"charms/db/backup.go":  "package db\n",
+		"charms/net/doc.go":    "// @Group Network\npackage net\n",
+		"charms/plain/doc.go":  "// Package plain has no group annotation.\npackage plain\n",
+		"charms/plain/main.go": "package plain\n",
+		"charms/prose/doc.go":  "// @Groups are declared in doc.go, see the @Group annotation.\npackage prose\n",
+	})
+
+	groups, err := FindGroups(filepath.Join(root, "charms"), root)
+	if err != nil {
+		t.Fatalf("FindGroups() error = %v", err)
+	}
+
+	want := []Group{
+		{Path: "db", Title: "Database", Description: "# Database", Icon: "🗄", Order: 2},
+		{Path: "net", Title: "Network"},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("FindGroups() = %+v, want %+v", groups, want)
+	}
+	for i := range want {
+		if groups[i] != want[i] {
+			t.Errorf("group %d = %+v, want %+v", i, groups[i], want[i])
+		}
+	}
+}
+
+func TestRenderRegistry_Imports(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		wantContext bool
+	}{
+		{
+			name:  "Empty charms directory",
+			files: map[string]string{"charms/.keep": ""},
+		},
+		{
+			name:  "Package without charms",
+			files: map[string]string{"charms/util.go": "package charms\n\nfunc helper() {}\n"},
+		},
+		{
+			name:        "Charm",
+			files:       map[string]string{"charms/run.go": "package charms\n\n// Run godoc\n// @Charm\nfunc Run() {}\n"},
+			wantContext: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeModule(t, tt.files)
+
+			charms, imports, err := FindCharms(filepath.Join(root, "charms"), root)
+			if err != nil {
+				t.Fatalf("FindCharms() error = %v", err)
+			}
+			content, err := RenderRegistry(DefaultOptions(), RegistryData{Imports: imports, Charms: charms})
+			if err != nil {
+				t.Fatalf("RenderRegistry() error = %v", err)
+			}
+
+			// An unused import would fail to compile
+			if got := strings.Contains(string(content), `"context"`); got != tt.wantContext {
+				t.Errorf("registry imports context = %v, want %v:\n%s", got, tt.wantContext, content)
+			}
+		})
+	}
+}
+
+func TestRenderRegistry_QuotedTitles(t *testing.T) {
+	root := writeModule(t, map[string]string{
+		"charms/db/doc.go":    "// @Group\n// @Title Data\\base \"main\"\npackage db\n",
+		"charms/db/backup.go": "package db\n\n// Backup godoc\n// @Charm\n// @Title Back\\up \"daily\"\nfunc Backup() {}\n",
+	})
+
+	charms, imports, err := FindCharms(filepath.Join(root, "charms"), root)
+	if err != nil {
+		t.Fatalf("FindCharms() error = %v", err)
+	}
+	groups, err := FindGroups(filepath.Join(root, "charms"), root)
+	if err != nil {
+		t.Fatalf("FindGroups() error = %v", err)
+	}
+	content, err := RenderRegistry(DefaultOptions(), RegistryData{Imports: imports, Charms: charms, Groups: groups})
+	if err != nil {
+		t.Fatalf("RenderRegistry() error = %v", err)
+	}
+
+	for _, want := range []string{`"Back\\up \"daily\""`, `"Data\\base \"main\""`} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("registry does not contain %s:\n%s", want, content)
+		}
+	}
+}
+
+func TestRenderRegistry_RawDocs(t *testing.T) {
+	root := writeModule(t, map[string]string{
+		"charms/backup.go": "package charms\n\n// Backup godoc\n// @Charm\n// @Description Copies the database to C:\\backups, see `backup --help`.\nfunc Backup() {}\n",
+	})
+
+	charms, imports, err := FindCharms(filepath.Join(root, "charms"), root)
+	if err != nil {
+		t.Fatalf("FindCharms() error = %v", err)
+	}
+	content, err := RenderRegistry(DefaultOptions(), RegistryData{Imports: imports, Charms: charms})
+	if err != nil {
+		t.Fatalf("RenderRegistry() error = %v", err)
+	}
+
+	// Doc and Description are raw string literals, backslashes are kept as they are
+	want := "Copies the database to C:\\backups, see ` + \"`\" + `backup --help` + \"`\" + `."
+	if got := strings.Count(string(content), want); got != 2 {
+		t.Errorf("registry contains %s %d times, want 2 (Doc and Description):\n%s", want, got, content)
+	}
+}
+
+func TestFindGroups_Duplicate(t *testing.T) {
+	root := writeModule(t, map[string]string{
+		"charms/db/a.go": "// @Group Database\npackage db\n",
+		"charms/db/b.go": "// Package db\npackage db\n",
+		"charms/db/c.go": "// @Group Databases\npackage db\n",
+	})
+
+	_, err := FindGroups(filepath.Join(root, "charms"), root)
+	if err == nil || !strings.Contains(err.Error(), "declared more than once") {
+		t.Errorf("FindGroups() error = %v, want a duplicate group error", err)
+	}
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/ImGajeed76/charmer/internal/docparser"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// FindGroups collects the menu metadata of all directories below dir.
+// A directory becomes a group if one of its files has a package doc comment with a @Group annotation.
+func FindGroups(dir string, moduleRoot string) ([]Group, error) {
+	pkgs, err := loadCharmPackages(dir, moduleRoot)
+	if err != nil {
+		return nil, err
+	}
+	return findGroups(pkgs, dir)
+}
+
+// findGroups collects the menu metadata from packages loaded by loadCharmPackages
+func findGroups(pkgs []*packages.Package, dir string) ([]Group, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving charms directory: %v", err)
+	}
+
+	var groups []Group
+	declared := make(map[string]string) // Group path to the file declaring it
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			if file.Doc == nil {
+				continue
+			}
+			docs := docparser.ParseAnnotations(file.Doc.Text())
+			if !docs.Group {
+				continue
+			}
+
+			relPath, err := filepath.Rel(absDir, packageDir(pkg))
+			if err != nil {
+				return nil, fmt.Errorf("error getting relative path: %v", err)
+			}
+			relPath = filepath.ToSlash(relPath)
+
+			path := pkg.Fset.Position(file.Pos()).Filename
+			if relPath == "." {
+				return nil, fmt.Errorf("error in file %s: @Group can't be used in the charms root directory", path)
+			}
+			if other, ok := declared[relPath]; ok {
+				return nil, fmt.Errorf("error in file %s: @Group is declared more than once for %s, also in %s", path, relPath, other)
+			}
+			declared[relPath] = path
+
+			title := docs.Title
+			if title == "" {
+				title = filepath.Base(relPath)
+			}
+
+			groups = append(groups, Group{
+				Path:        relPath,
+				Title:       title,
+				Description: docs.Description,
+				Icon:        docs.Icon,
+				Order:       docs.Order,
+			})
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Path < groups[j].Path
+	})
+
+	return groups, nil
+}
@@ -0,0 +1,171 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// reservedAliases are the imports used by the generated registry itself
+var reservedAliases = map[string]struct{}{
+	"context": {},
+	"models":  {},
+	"time":    {},
+	"path":    {},
+}
+
+var invalidAliasChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// loadCharmPackages loads all packages below dir with their syntax trees.
+// Test files are not loaded and files excluded by build constraints are reported as ignored files.
+func loadCharmPackages(dir string, moduleRoot string) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax,
+		Dir:  moduleRoot,
+		Fset: token.NewFileSet(),
+	}
+
+	absRoot, err := filepath.Abs(moduleRoot)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving module root: %v", err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving charms directory: %v", err)
+	}
+	relDir, err := filepath.Rel(absRoot, absDir)
+	if err != nil {
+		return nil, fmt.Errorf("error getting relative path: %v", err)
+	}
+
+	pattern := "./" + filepath.ToSlash(relDir) + "/..."
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("error loading charm packages: %v", err)
+	}
+
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].PkgPath < pkgs[j].PkgPath
+	})
+
+	return pkgs, nil
+}
+
+// packageDir returns the directory of a loaded package
+func packageDir(pkg *packages.Package) string {
+	if len(pkg.GoFiles) > 0 {
+		return filepath.Dir(pkg.GoFiles[0])
+	}
+	if len(pkg.IgnoredFiles) > 0 {
+		return filepath.Dir(pkg.IgnoredFiles[0])
+	}
+	return ""
+}
+
+// importAliases assigns every charm package a unique import alias.
+// Packages keep their own name unless it is used by another charm package
+// or by the registry itself, in which case the alias is derived from the directory.
+func importAliases(pkgs []*packages.Package, moduleRoot string) (map[string]string, error) {
+	nameCount := make(map[string]int)
+	for _, pkg := range pkgs {
+		nameCount[pkg.Name]++
+	}
+
+	aliases := make(map[string]string)
+	used := make(map[string]struct{})
+	for name := range reservedAliases {
+		used[name] = struct{}{}
+	}
+
+	for _, pkg := range pkgs {
+		alias := pkg.Name
+		_, reserved := reservedAliases[alias]
+		if nameCount[alias] > 1 || reserved {
+			relPath, err := filepath.Rel(moduleRoot, packageDir(pkg))
+			if err != nil {
+				return nil, fmt.Errorf("error getting relative path: %v", err)
+			}
+			alias = strings.Trim(invalidAliasChars.ReplaceAllString(filepath.ToSlash(relPath), "_"), "_")
+			if alias == "" || (alias[0] >= '0' && alias[0] <= '9') {
+				alias = "charms_" + alias
+			}
+		}
+
+		// Fall back to a numeric suffix if the alias is still taken
+		unique := alias
+		for i := 2; ; i++ {
+			if _, taken := used[unique]; !taken {
+				break
+			}
+			unique = fmt.Sprintf("%s%d", alias, i)
+		}
+
+		used[unique] = struct{}{}
+		aliases[pkg.PkgPath] = unique
+	}
+
+	return aliases, nil
+}
+
+// checkExcludedFiles returns an error if a file that is not part of the build declares a charm
+func checkExcludedFiles(files []string, reason string) error {
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+
+		parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("error parsing file %s: %v", file, err)
+		}
+
+		for _, decl := range parsed.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && isCharm(fn) {
+				return fmt.Errorf("charm %s in %s is %s, charms must be in regular package files", fn.Name.Name, file, reason)
+			}
+		}
+	}
+	return nil
+}
+
+// testFiles returns the _test.go files in a directory
+func testFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), "_test.go") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// isCharm reports whether a function is annotated with @Charm
+func isCharm(fn *ast.FuncDecl) bool {
+	return fn.Doc != nil && strings.Contains(fn.Doc.Text(), "@Charm")
+}
+
+// hasCharms reports whether any file of the package declares a charm
+func hasCharms(pkg *packages.Package) bool {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && isCharm(fn) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/ImGajeed76/charmer/internal/docparser"
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"go/ast"
+	"go/doc"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const pathImportPath = "github.com/ImGajeed76/charmer/pkg/charmer/path"
+
+type CharmParameter struct {
+	Name    string
+	Type    string // Name of the models.ParamType constant
+	Prompt  string
+	Default string
+	Regex   string
+	Options []string
+	Secret  bool
+	Arg     string // Expression converting the form value to the Go argument
+}
+
+var paramTypeNames = map[models.ParamType]string{
+	models.ParamString:   "ParamString",
+	models.ParamInt:      "ParamInt",
+	models.ParamFloat:    "ParamFloat",
+	models.ParamBool:     "ParamBool",
+	models.ParamDuration: "ParamDuration",
+	models.ParamEnum:     "ParamEnum",
+	models.ParamPath:     "ParamPath",
+}
+
+// Signature describes how the generated ExecuteFunc calls a charm function
+type Signature struct {
+	Params       []CharmParameter
+	HasContext   bool // First parameter is a context.Context
+	ReturnsError bool
+	Imports      []Import // Extra imports needed for the argument conversions
+}
+
+// CallArgs returns the argument list used to call the charm function
+func (s Signature) CallArgs() string {
+	args := make([]string, 0, len(s.Params)+1)
+	if s.HasContext {
+		args = append(args, "ctx")
+	}
+	for _, param := range s.Params {
+		args = append(args, param.Arg)
+	}
+	return strings.Join(args, ", ")
+}
+
+// resolveSignature checks that a charm function has a supported signature
+// (func(...), func(...) error, optionally taking a context.Context first)
+// and maps its parameters to form parameters.
+func resolveSignature(fn *ast.FuncDecl, file *ast.File, docPkg *doc.Package, pkgName string, docs docparser.Docs) (Signature, error) {
+	fileImports := importNames(file)
+	sig := Signature{}
+
+	if results := fn.Type.Results; results != nil && len(results.List) > 0 {
+		ident, ok := results.List[0].Type.(*ast.Ident)
+		if len(results.List) != 1 || len(results.List[0].Names) > 1 || !ok || ident.Name != "error" {
+			return sig, fmt.Errorf("charm %s: unsupported return type (charms may only return error)", fn.Name.Name)
+		}
+		sig.ReturnsError = true
+	}
+
+	params, imports, err := resolveParams(fn, fileImports, docPkg, pkgName, docs, &sig.HasContext)
+	if err != nil {
+		return sig, err
+	}
+	sig.Params = params
+	sig.Imports = imports
+
+	return sig, nil
+}
+
+// resolveParams maps the parameters of a charm function to form parameters.
+// A leading context.Context parameter is skipped and reported through hasContext.
+func resolveParams(fn *ast.FuncDecl, fileImports map[string]string, docPkg *doc.Package, pkgName string, docs docparser.Docs, hasContext *bool) ([]CharmParameter, []Import, error) {
+	var params []CharmParameter
+	var imports []Import
+
+	for fieldIndex, field := range fn.Type.Params.List {
+		if len(field.Names) == 0 {
+			return nil, nil, fmt.Errorf("charm %s: parameters must be named", fn.Name.Name)
+		}
+
+		if sel, ok := field.Type.(*ast.SelectorExpr); ok && isImportedType(sel, fileImports, "context", "Context") {
+			if fieldIndex != 0 || len(field.Names) != 1 {
+				return nil, nil, fmt.Errorf("charm %s: context.Context must be the first parameter", fn.Name.Name)
+			}
+			*hasContext = true
+			continue
+		}
+
+		for _, name := range field.Names {
+			index := len(params)
+			arg := fmt.Sprintf("args[%d]", index)
+
+			var paramType models.ParamType
+			var options []string
+			switch t := field.Type.(type) {
+			case *ast.Ident:
+				switch t.Name {
+				case "string":
+					paramType, arg = models.ParamString, arg+".(string)"
+				case "int":
+					paramType, arg = models.ParamInt, arg+".(int)"
+				case "float64":
+					paramType, arg = models.ParamFloat, arg+".(float64)"
+				case "float32":
+					paramType, arg = models.ParamFloat, "float32("+arg+".(float64))"
+				case "bool":
+					paramType, arg = models.ParamBool, arg+".(bool)"
+				default:
+					consts, err := enumConstants(docPkg, t.Name)
+					if err != nil {
+						return nil, nil, fmt.Errorf("charm %s, parameter %s: %v", fn.Name.Name, name.Name, err)
+					}
+					qualified := make([]string, len(consts))
+					for i, c := range consts {
+						qualified[i] = pkgName + "." + c
+					}
+					paramType, options = models.ParamEnum, consts
+					arg = fmt.Sprintf("[]%s.%s{%s}[%s.(int)]", pkgName, t.Name, strings.Join(qualified, ", "), arg)
+				}
+			case *ast.SelectorExpr:
+				if !isImportedType(t, fileImports, "time", "Duration") {
+					return nil, nil, unsupportedParamError(fn.Name.Name, name.Name)
+				}
+				paramType, arg = models.ParamDuration, arg+".(time.Duration)"
+				imports = append(imports, Import{Package: "time", ParentPath: "time"})
+			case *ast.StarExpr:
+				sel, ok := t.X.(*ast.SelectorExpr)
+				if !ok || !isImportedType(sel, fileImports, pathImportPath, "Path") {
+					return nil, nil, unsupportedParamError(fn.Name.Name, name.Name)
+				}
+				paramType, arg = models.ParamPath, arg+".(*path.Path)"
+				imports = append(imports, Import{Package: "path", ParentPath: pathImportPath})
+			default:
+				return nil, nil, unsupportedParamError(fn.Name.Name, name.Name)
+			}
+
+			param := CharmParameter{
+				Name:    name.Name,
+				Type:    paramTypeNames[paramType],
+				Options: options,
+				Arg:     arg,
+			}
+			if annotation, ok := docs.Param(name.Name); ok {
+				param.Prompt = annotation.Prompt
+				param.Default = annotation.Default
+				param.Regex = annotation.Regex
+				param.Secret = annotation.Secret
+			}
+
+			if err := validateParam(param, paramType); err != nil {
+				return nil, nil, fmt.Errorf("charm %s, parameter %s: %v", fn.Name.Name, name.Name, err)
+			}
+
+			params = append(params, param)
+		}
+	}
+
+	// Catch typos in @Param annotations
+	for _, annotation := range docs.Params {
+		found := false
+		for _, param := range params {
+			if param.Name == annotation.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("charm %s: @Param %s does not match any parameter", fn.Name.Name, annotation.Name)
+		}
+	}
+
+	return params, imports, nil
+}
+
+// validateParam checks the annotation values at generate time so broken
+// defaults and regexes are reported before the charm is ever run
+func validateParam(param CharmParameter, paramType models.ParamType) error {
+	if param.Regex != "" {
+		if _, err := regexp.Compile(param.Regex); err != nil {
+			return fmt.Errorf("invalid regex: %v", err)
+		}
+	}
+	if param.Default != "" {
+		charmParam := models.CharmParam{Name: param.Name, Type: paramType, Options: param.Options}
+		if _, err := charmParam.Parse(param.Default); err != nil {
+			return fmt.Errorf("invalid default: %v", err)
+		}
+	}
+	return nil
+}
+
+// enumConstants returns the exported constants declared for a named type in the charm package
+func enumConstants(docPkg *doc.Package, typeName string) ([]string, error) {
+	if !ast.IsExported(typeName) {
+		return nil, fmt.Errorf("unsupported type %s (enum types must be exported)", typeName)
+	}
+
+	for _, t := range docPkg.Types {
+		if t.Name != typeName {
+			continue
+		}
+
+		var consts []string
+		for _, value := range t.Consts {
+			for _, name := range value.Names {
+				if ast.IsExported(name) {
+					consts = append(consts, name)
+				}
+			}
+		}
+		if len(consts) == 0 {
+			return nil, fmt.Errorf("enum type %s has no exported constants", typeName)
+		}
+		return consts, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", typeName)
+}
+
+// importNames maps the local name of each import in a file to its import path
+func importNames(file *ast.File) map[string]string {
+	names := make(map[string]string)
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importPath[strings.LastIndex(importPath, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		names[name] = importPath
+	}
+	return names
+}
+
+func isImportedType(sel *ast.SelectorExpr, fileImports map[string]string, importPath, typeName string) bool {
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	return fileImports[pkg.Name] == importPath && sel.Sel.Name == typeName
+}
+
+func unsupportedParamError(fnName, paramName string) error {
+	return fmt.Errorf("charm %s, parameter %s: unsupported type (supported: string, int, float64, float32, bool, time.Duration, *path.Path and enums)", fnName, paramName)
+}
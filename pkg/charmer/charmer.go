@@ -1,26 +1,289 @@
 package charmer
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/ImGajeed76/charmer/pkg/charmer/console"
+	"github.com/ImGajeed76/charmer/pkg/charmer/jobs"
 	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"github.com/ImGajeed76/charmer/pkg/charmer/state"
+	"github.com/ImGajeed76/charmer/pkg/charmer/theme"
 	tea "github.com/charmbracelet/bubbletea"
 	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 )
 
+// themeEnv selects a built-in theme by name: auto, dark, light or high-contrast
+const themeEnv = "CHARMER_THEME"
+
+// Run opens the interactive charm selector. When the first command line argument is a command like run or list,
+// it is run headlessly instead (see RunHeadless) and the process exits with its exit code.
 func Run(charms map[string]models.CharmFunc) {
-	selectedPath := ""
+	RunWithOptions(charms, Options{})
+}
+
+// RunSession works like Run, but returns to the charm selector after a charm finished.
+// The selector keeps its path, search term and cursor, so several charms can be run in one session.
+func RunSession(charms map[string]models.CharmFunc) {
+	RunWithOptions(charms, Options{Session: true})
+}
+
+// RunWithOptions works like Run, configured by options
+func RunWithOptions(charms map[string]models.CharmFunc, options Options) {
+	if args := commandArgs(os.Args[1:]); len(args) > 0 && isCommand(args[0]) {
+		code := RunHeadless(charms, args, options)
+		// os.Exit skips deferred calls, so the hook has to run first
+		if options.Hooks.OnExit != nil {
+			options.Hooks.OnExit()
+		}
+		os.Exit(code)
+	}
+
+	if options.Hooks.OnExit != nil {
+		defer options.Hooks.OnExit()
+	}
+
+	console.SetTheme(resolveTheme(options.Theme))
+
+	store := openState(options.configName())
+	console.SetHistoryStore(store)
+	selectedPath := startPath(charms, options.StartPath)
+	m := console.NewCharmSelectorModel(charms, &selectedPath).
+		WithHeader(options.title(), options.Banner).
+		WithGroups(options.Groups).
+		WithState(store).
+		WithKeyMap(loadKeyMap(options.configName(), options.keyMap()))
+
+	// Jobs keep running in the background, so the selector has to stay open after a charm finished
+	session := options.Session || options.BackgroundJobs
+	var manager *jobs.Manager
+	if options.BackgroundJobs {
+		manager = jobs.NewManager()
+		m.WithJobs(manager)
+		defer shutdownJobs(manager)
+	}
+
+	for {
+		p := tea.NewProgram(m, options.programOptions()...)
+		if _, err := p.Run(); err != nil {
+			log.Fatal(err)
+		}
+
+		if selectedPath == "" {
+			return
+		}
+
+		// get the selected charm and execute it
+		charm := charms[strings.TrimSuffix(selectedPath, "/")]
+
+		// Charms with parameters get a form built from their parameter declarations,
+		// history entries that are run again reuse their recorded arguments
+		var args []any
+		var err error
+		if entry, ok := m.Replay(); ok {
+			args, err = replayArgs(charm, entry)
+		} else {
+			args, err = console.ParamForm(charm.Params)
+		}
+		if err != nil {
+			if !session {
+				log.Println(err)
+				return
+			}
+			m.Resume()
+			continue
+		}
+
+		confirmed, err := confirm(charm)
+		if err != nil || !confirmed {
+			if !session {
+				fmt.Println("Charm cancelled")
+				return
+			}
+			m.Resume()
+			continue
+		}
+
+		store.AddRecent(charm.Path)
+		_ = store.Save()
+
+		// Background jobs report their result in the jobs panel, the selector stays open
+		if m.Background() {
+			if err := startJob(manager, charm, args, options.Hooks); err != nil {
+				log.Println(err)
+			}
+			m.Resume()
+			continue
+		}
+
+		if charm.Deprecated != "" {
+			fmt.Println(console.WarningBanner("Deprecated: " + charm.Deprecated))
+		}
+
+		duration, err := options.runCharm(charm, args, store)
+
+		if !session {
+			reportError(charm, err, options.CrashReportDir)
+			return
+		}
+
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			reportPanic(panicErr, options.CrashReportDir)
+			m.Resume()
+			continue
+		}
+
+		back, screenErr := console.ResultScreen(console.ResultScreenOptions{
+			Title:    charmTitle(charm),
+			Err:      err,
+			Duration: duration,
+		})
+		if screenErr != nil {
+			log.Fatal(screenErr)
+		}
+		if !back {
+			return
+		}
+		m.Resume()
+	}
+}
+
+// shutdownJobs cancels the background jobs that are still running when the application exits
+func shutdownJobs(manager *jobs.Manager) {
+	if running := manager.Running(); running > 0 {
+		fmt.Printf("Cancelling %d background jobs\n", running)
+	}
+	manager.Shutdown()
+}
 
-	m := console.NewCharmSelectorModel(charms, &selectedPath)
-	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseAllMotion())
-	if _, err := p.Run(); err != nil {
-		log.Fatal(err)
+// startPath returns the folder the selector opens in, or the root if no charm is inside it
+func startPath(charms map[string]models.CharmFunc, folder string) string {
+	folder = strings.Trim(folder, "/")
+	if folder == "" {
+		return ""
 	}
 
-	// get the selected charm and execute it
-	if selectedPath != "" {
-		selectedPath = strings.TrimSuffix(selectedPath, "/")
-		charm := charms[selectedPath]
-		charm.Execute.(func())()
+	folder += "/"
+	for charmPath := range charms {
+		if strings.HasPrefix(charmPath, folder) {
+			return folder
+		}
+	}
+	return ""
+}
+
+// openState loads the favorites and recently used charms of the current user.
+// If the state file cannot be read, an in-memory store is used, so the menu still works.
+func openState(app string) *state.Store {
+	store, err := state.Open(app)
+	if err != nil {
+		return &state.Store{}
+	}
+	return store
+}
+
+// resolveTheme picks the theme named by CHARMER_THEME, then the application's theme,
+// then detects one from the terminal background
+func resolveTheme(appTheme *theme.Theme) theme.Theme {
+	if name := os.Getenv(themeEnv); name != "" {
+		if t, ok := theme.ByName(name); ok {
+			return t
+		}
+		log.Printf("Ignoring unknown theme '%s' in %s", name, themeEnv)
+	}
+	if appTheme != nil {
+		return *appTheme
+	}
+	return theme.Detect()
+}
+
+// loadKeyMap applies the user's keymap.json on top of the given key bindings.
+// An invalid file is reported and ignored, so a typo never locks the user out of the menu.
+func loadKeyMap(app string, keys console.SelectorKeyMap) console.SelectorKeyMap {
+	dir, err := state.Dir(app)
+	if err != nil {
+		return keys
+	}
+
+	keymapPath := filepath.Join(dir, "keymap.json")
+	data, err := os.ReadFile(keymapPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Ignoring keymap %s: %v", keymapPath, err)
+		}
+		return keys
+	}
+
+	var config console.KeyMapConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		log.Printf("Ignoring keymap %s: %v", keymapPath, err)
+		return keys
+	}
+
+	configured, err := keys.WithConfig(config)
+	if err != nil {
+		log.Printf("Ignoring keymap %s: %v", keymapPath, err)
+		return keys
+	}
+	return configured
+}
+
+// execute runs a charm through the middleware with a context that is cancelled on Ctrl+C or SIGTERM
+func execute(charm models.CharmFunc, args []any, middleware []Middleware) error {
+	if charm.Execute == nil {
+		return fmt.Errorf("charm %s has no execute function", charm.Path)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Restore the default signal behavior after the first signal,
+	// so a second Ctrl+C kills a charm that ignores its context
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	// The charm is recovered inside the chain, so middleware sees its panics as PanicError.
+	// The outer recovery catches panics of the middleware itself.
+	handler := chain(requireEnv(func(ctx context.Context, charm models.CharmFunc, args []any) error {
+		return callRecovered(charm, func() error {
+			return charm.Execute(ctx, args)
+		})
+	}), middleware)
+	return callRecovered(charm, func() error {
+		return handler(ctx, charm, args)
+	})
+}
+
+// reportError shows the error returned by a charm, if any
+func reportError(charm models.CharmFunc, err error, crashReportDir string) {
+	var panicErr *PanicError
+	switch {
+	case err == nil:
+	case errors.As(err, &panicErr):
+		reportPanic(panicErr, crashReportDir)
+	case errors.Is(err, context.Canceled):
+		fmt.Println("Charm cancelled")
+	default:
+		if screenErr := console.ErrorScreen(err, console.ErrorScreenOptions{
+			Title: fmt.Sprintf("%s failed", charmTitle(charm)),
+		}); screenErr != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func charmTitle(charm models.CharmFunc) string {
+	if charm.Title != "" {
+		return charm.Title
 	}
+	return charm.Path
 }
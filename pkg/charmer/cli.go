@@ -0,0 +1,481 @@
+package charmer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ImGajeed76/charmer/pkg/charmer/console"
+	"github.com/ImGajeed76/charmer/pkg/charmer/jobs"
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"github.com/ImGajeed76/charmer/pkg/charmer/state"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Exit codes returned by RunHeadless
+const (
+	exitOK        = 0
+	exitError     = 1
+	exitUsage     = 2
+	exitCancelled = 130
+)
+
+// confirmFlag confirms charms with a @Confirm annotation in headless mode
+const confirmFlag = "yes"
+
+// lastFlag runs the most recently used charm instead of a charm path
+const lastFlag = "last"
+
+// promptSeparator ends the parameter flags of the run command, flags after it answer prompts inside the charm
+const promptSeparator = "--"
+
+// tagFlag filters the list command by tags, multiple tags are comma separated
+const tagFlag = "tag"
+
+// envPrefix is prepended to the upper-cased parameter name to read parameters from the environment
+const envPrefix = "CHARMER_"
+
+// jobArgPrefix is prepended to the upper-cased parameter name to pass arguments to background jobs.
+// It is separate from envPrefix, so parameters like "job" or "theme" can't override CHARMER_JOB or CHARMER_THEME.
+const jobArgPrefix = "CHARMER_ARG_"
+
+// RunHeadless runs the command line interface without opening the TUI and returns the exit code.
+// Supported commands are:
+//
+//	run <path> [--param=value ...] [-- --prompt=value ...]
+//	run --last [--param=value ...]
+//	run --replay <id> [--param=value ...]
+//	list [--tag=a,b]
+//	history
+//	help [path]
+//
+// Only the first options are used, see RunWithOptions.
+func RunHeadless(charms map[string]models.CharmFunc, args []string, opts ...Options) int {
+	var options Options
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	return runHeadless(charms, args, options, openState(options.configName()), os.Stdout, os.Stderr)
+}
+
+// isCommand reports whether the first argument is a command of RunHeadless.
+// Run opens the TUI for any other arguments.
+func isCommand(arg string) bool {
+	switch arg {
+	case "run", "list", "history", "help", "-h", "--help":
+		return true
+	}
+	return false
+}
+
+// commandArgs returns the arguments meant for the command line interface.
+// Flags the go test binary passes along, like -test.v, are dropped, so tests can call Run.
+func commandArgs(args []string) []string {
+	var command []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-test.") {
+			command = append(command, arg)
+		}
+	}
+	return command
+}
+
+func runHeadless(charms map[string]models.CharmFunc, args []string, options Options, store *state.Store, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "list":
+		flags, err := parseFlags(args[1:])
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		for key := range flags {
+			if key != tagFlag {
+				_, _ = fmt.Fprintf(stderr, "Unknown flag '--%s'\n", key)
+				return exitUsage
+			}
+		}
+		listCharms(charms, splitTags(flags[tagFlag]), stdout)
+		return exitOK
+	case "help", "-h", "--help":
+		if len(args) < 2 {
+			printUsage(stdout)
+			return exitOK
+		}
+		charm, ok := lookupCharm(charms, args[1])
+		if !ok {
+			_, _ = fmt.Fprintf(stderr, "Unknown charm '%s'\n", args[1])
+			return exitUsage
+		}
+		printCharmHelp(charm, stdout)
+		return exitOK
+	case "run":
+		if len(args) < 2 {
+			_, _ = fmt.Fprintln(stderr, "Missing charm path")
+			printUsage(stderr)
+			return exitUsage
+		}
+		charmPath, flagArgs := args[1], args[2:]
+		var replay *state.HistoryEntry
+		switch {
+		case charmPath == "--"+lastFlag:
+			last, ok := store.Last()
+			if !ok {
+				_, _ = fmt.Fprintln(stderr, "No charm has been run yet")
+				return exitUsage
+			}
+			charmPath = last
+		case charmPath == "--"+replayFlag || strings.HasPrefix(charmPath, "--"+replayFlag+"="):
+			id, ok := strings.CutPrefix(charmPath, "--"+replayFlag+"=")
+			if !ok {
+				if len(flagArgs) == 0 {
+					_, _ = fmt.Fprintln(stderr, "Missing history entry ID, see the history command")
+					return exitUsage
+				}
+				id, flagArgs = flagArgs[0], flagArgs[1:]
+			}
+			entry, err := findHistoryEntry(store, id)
+			if err != nil {
+				_, _ = fmt.Fprintln(stderr, err)
+				return exitUsage
+			}
+			charmPath, replay = entry.Charm, &entry
+		}
+		return runCharmHeadless(charms, charmPath, flagArgs, replay, options, store, stderr)
+	case "history":
+		if len(args) > 1 {
+			_, _ = fmt.Fprintf(stderr, "Unexpected argument '%s'\n", args[1])
+			return exitUsage
+		}
+		entries, err := store.History()
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		listHistory(entries, stdout)
+		return exitOK
+	default:
+		_, _ = fmt.Fprintf(stderr, "Unknown command '%s'\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+}
+
+// runCharmHeadless runs a charm with its arguments read from flags. If replay is set,
+// the arguments recorded in the history entry are used for parameters without a flag.
+func runCharmHeadless(charms map[string]models.CharmFunc, charmPath string, flagArgs []string, replay *state.HistoryEntry, options Options, store *state.Store, stderr io.Writer) int {
+	charm, ok := lookupCharm(charms, charmPath)
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "Unknown charm '%s'\n", charmPath)
+		return exitUsage
+	}
+
+	// Flags after the separator answer prompts inside the charm, they can't be checked up front
+	var promptArgs []string
+	if i := slices.Index(flagArgs, promptSeparator); i >= 0 {
+		flagArgs, promptArgs = flagArgs[:i], flagArgs[i+1:]
+	}
+	flags, err := parseFlags(flagArgs)
+	if err == nil {
+		err = checkFlags(charm, flags)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	promptFlags, err := parseFlags(promptArgs)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	// Destructive charms have to be confirmed explicitly, there is nobody to ask
+	if charm.Confirm != nil {
+		confirmed, err := strconv.ParseBool(flags[confirmFlag])
+		if _, ok := flags[confirmFlag]; ok && err != nil {
+			_, _ = fmt.Fprintf(stderr, "invalid value for --%s: %q is not a boolean\n", confirmFlag, flags[confirmFlag])
+			return exitUsage
+		}
+		if !confirmed {
+			_, _ = fmt.Fprintf(stderr, "%s requires confirmation: %s\nPass --%s to run it\n", charm.Path, charm.Confirm.Message, confirmFlag)
+			return exitUsage
+		}
+	}
+
+	if replay != nil {
+		if err := replayFlags(charm, *replay, flags); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	args, err := resolveArgs(charm.Params, flags)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	// Prompts inside the charm read their values from the flags instead of opening a TUI
+	values := maps.Clone(flags)
+	maps.Copy(values, promptFlags)
+	console.SetHeadless(values)
+
+	// The application that started a background job already did the bookkeeping
+	if !jobs.IsJob() {
+		if charm.Deprecated != "" {
+			_, _ = fmt.Fprintf(stderr, "Warning: %s is deprecated: %s\n", charm.Path, charm.Deprecated)
+		}
+
+		store.AddRecent(charm.Path)
+		_ = store.Save()
+	}
+
+	_, err = options.runCharm(charm, args, store)
+	var panicErr *PanicError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &panicErr):
+		_, _ = fmt.Fprintf(stderr, "Error: %v\n\n%s", panicErr, panicErr.Stack)
+		if reportPath, reportErr := writeCrashReport(panicErr, options.CrashReportDir); reportErr != nil {
+			_, _ = fmt.Fprintln(stderr, reportErr)
+		} else if reportPath != "" {
+			_, _ = fmt.Fprintf(stderr, "Crash report written to %s\n", reportPath)
+		}
+		return exitError
+	case errors.Is(err, context.Canceled):
+		_, _ = fmt.Fprintln(stderr, "Charm cancelled")
+		return exitCancelled
+	default:
+		_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+}
+
+// lookupCharm finds a charm by its registry path, ignoring surrounding slashes
+func lookupCharm(charms map[string]models.CharmFunc, charmPath string) (models.CharmFunc, bool) {
+	charm, ok := charms[strings.Trim(charmPath, "/")]
+	return charm, ok
+}
+
+// parseFlags parses --key=value, --key value and bare --key (true) flags
+func parseFlags(args []string) (map[string]string, error) {
+	flags := make(map[string]string)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") || len(arg) == 2 {
+			return nil, fmt.Errorf("unexpected argument '%s'", arg)
+		}
+
+		key, value, hasValue := strings.Cut(arg[2:], "=")
+		if !hasValue {
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+				value = args[i+1]
+				i++
+			} else {
+				value = "true"
+			}
+		}
+		flags[key] = value
+	}
+
+	return flags, nil
+}
+
+// checkFlags returns an error for the first flag that is neither a parameter of the charm nor --yes,
+// so a mistyped flag fails instead of the parameter silently falling back to the environment or its default
+func checkFlags(charm models.CharmFunc, flags map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		isParam := slices.ContainsFunc(charm.Params, func(param models.CharmParam) bool {
+			return param.Name == name
+		})
+		if !isParam && name != confirmFlag {
+			return fmt.Errorf("unknown flag '--%s' for %s, see help %s", name, charm.Path, charm.Path)
+		}
+	}
+	return nil
+}
+
+// resolveArgs reads each parameter from the flags, the environment or its default, in that order
+func resolveArgs(params []models.CharmParam, flags map[string]string) ([]any, error) {
+	args := make([]any, 0, len(params))
+
+	for _, param := range params {
+		raw, ok := flags[param.Name]
+		if !ok && jobs.IsJob() {
+			raw, ok = os.LookupEnv(jobArgEnvName(param.Name))
+		}
+		if !ok {
+			raw, ok = os.LookupEnv(paramEnvName(param.Name))
+		}
+		if !ok {
+			raw, ok = param.Default, param.Default != ""
+		}
+		if !ok {
+			return nil, fmt.Errorf("missing required parameter --%s (or %s)", param.Name, paramEnvName(param.Name))
+		}
+
+		value, err := param.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+
+	return args, nil
+}
+
+func paramEnvName(name string) string {
+	return envPrefix + strings.ToUpper(name)
+}
+
+// jobArgEnvName returns the environment variable a background job reads the parameter from
+func jobArgEnvName(name string) string {
+	return jobArgPrefix + strings.ToUpper(name)
+}
+
+// listCharms prints all visible charms that have every one of the given tags
+func listCharms(charms map[string]models.CharmFunc, tags []string, w io.Writer) {
+	paths := make([]string, 0, len(charms))
+	for charmPath, charm := range charms {
+		// Hidden charms can be run, but are not listed
+		if !charm.Hidden && hasTags(charm, tags) {
+			paths = append(paths, charmPath)
+		}
+	}
+	sort.Strings(paths)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, charmPath := range paths {
+		charm := charms[charmPath]
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", charmPath, charm.Title, formatTags(charm.Tags))
+	}
+	_ = tw.Flush()
+}
+
+func hasTags(charm models.CharmFunc, tags []string) bool {
+	for _, tag := range tags {
+		if !charm.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func formatTags(tags []string) string {
+	formatted := make([]string, len(tags))
+	for i, tag := range tags {
+		formatted[i] = "#" + tag
+	}
+	return strings.Join(formatted, " ")
+}
+
+// findHistoryEntry looks up a history entry by its ID as given on the command line
+func findHistoryEntry(store *state.Store, id string) (state.HistoryEntry, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return state.HistoryEntry{}, fmt.Errorf("invalid history entry ID '%s'", id)
+	}
+	entry, ok, err := store.HistoryEntry(n)
+	if err != nil {
+		return state.HistoryEntry{}, err
+	}
+	if !ok {
+		return state.HistoryEntry{}, fmt.Errorf("no history entry with ID %d", n)
+	}
+	return entry, nil
+}
+
+// listHistory prints the recorded charm runs, most recent first
+func listHistory(entries []state.HistoryEntry, w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, entry := range entries {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n",
+			entry.ID,
+			entry.Start.Local().Format("2006-01-02 15:04:05"),
+			entry.Charm,
+			entry.Status,
+			entry.Duration().Round(time.Millisecond))
+	}
+	_ = tw.Flush()
+}
+
+func printCharmHelp(charm models.CharmFunc, w io.Writer) {
+	_, _ = fmt.Fprintf(w, "%s (%s)\n", charm.Title, charm.Path)
+	if charm.Deprecated != "" {
+		_, _ = fmt.Fprintf(w, "\nDeprecated: %s\n", charm.Deprecated)
+	}
+	if len(charm.Tags) > 0 {
+		_, _ = fmt.Fprintf(w, "\nTags: %s\n", formatTags(charm.Tags))
+	}
+	if charm.Description != "" {
+		_, _ = fmt.Fprintf(w, "\n%s\n", charm.Description)
+	}
+
+	_, _ = fmt.Fprintf(w, "\nUsage:\n  %s run %s", programName(), charm.Path)
+	for _, param := range charm.Params {
+		_, _ = fmt.Fprintf(w, " --%s=<%s>", param.Name, param.Type)
+	}
+	if charm.Confirm != nil {
+		_, _ = fmt.Fprintf(w, " --%s", confirmFlag)
+	}
+	_, _ = fmt.Fprintln(w)
+
+	if len(charm.Params) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintln(w, "\nParameters:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, param := range charm.Params {
+		details := param.Label()
+		if len(param.Options) > 0 {
+			details += fmt.Sprintf(" [%s]", strings.Join(param.Options, "|"))
+		}
+		if param.Default != "" {
+			details += fmt.Sprintf(" (default: %s)", param.Default)
+		}
+		_, _ = fmt.Fprintf(tw, "  --%s\t%s\t%s\t%s\n", param.Name, param.Type, paramEnvName(param.Name), details)
+	}
+	_ = tw.Flush()
+}
+
+func printUsage(w io.Writer) {
+	name := programName()
+	_, _ = fmt.Fprintf(w, `Usage:
+  %[1]s                                 Open the interactive charm selector
+  %[1]s run <path> [--param=value ...]  Run a charm without the TUI
+  %[1]s run --last [--param=value ...]  Run the most recently used charm again
+  %[1]s run --replay <id> [...]         Run a charm again with the arguments from the run history
+  %[1]s list [--tag=a,b]                List all charms, optionally only those with all given tags
+  %[1]s history                         List the recorded charm runs
+  %[1]s help [path]                     Show help for a charm
+`, name)
+}
+
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
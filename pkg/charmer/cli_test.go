@@ -0,0 +1,428 @@
+package charmer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"github.com/ImGajeed76/charmer/pkg/charmer/state"
+)
+
+func testCharms(got *[]any) map[string]models.CharmFunc {
+	return map[string]models.CharmFunc{
+		"deploy/staging": {
+			Name:  "Staging",
+			Path:  "deploy/staging",
+			Title: "Deploy Staging",
+			Tags:  []string{"deploy", "staging"},
+			Params: []models.CharmParam{
+				{Name: "name", Type: models.ParamString},
+				{Name: "replicas", Type: models.ParamInt, Default: "2"},
+				{Name: "force", Type: models.ParamBool, Default: "false"},
+			},
+			Execute: func(ctx context.Context, args []any) error {
+				*got = args
+				return nil
+			},
+		},
+		"fail": {
+			Name:  "Fail",
+			Path:  "fail",
+			Title: "Fail",
+			Tags:  []string{"staging"},
+			Execute: func(ctx context.Context, args []any) error {
+				return errors.New("boom")
+			},
+		},
+		"drop": {
+			Name:    "Drop",
+			Path:    "drop",
+			Title:   "Drop",
+			Confirm: &models.Confirmation{Message: "Drop the database?"},
+			Execute: func(ctx context.Context, args []any) error {
+				*got = []any{"dropped"}
+				return nil
+			},
+		},
+		"backup": {
+			Name:   "Backup",
+			Path:   "backup",
+			Title:  "Backup",
+			Params: []models.CharmParam{{Name: "dst", Type: models.ParamPath}},
+			Execute: func(ctx context.Context, args []any) error {
+				*got = args
+				return nil
+			},
+		},
+		"panic": {
+			Name:  "Panic",
+			Path:  "panic",
+			Title: "Panic",
+			Execute: func(ctx context.Context, args []any) error {
+				panic("boom")
+			},
+		},
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "Equals syntax",
+			args: []string{"--name=foo"},
+			want: map[string]string{"name": "foo"},
+		},
+		{
+			name: "Separate value",
+			args: []string{"--name", "foo", "--count", "3"},
+			want: map[string]string{"name": "foo", "count": "3"},
+		},
+		{
+			name: "Bare flag is true",
+			args: []string{"--force", "--name=foo"},
+			want: map[string]string{"force": "true", "name": "foo"},
+		},
+		{
+			name:    "Positional argument",
+			args:    []string{"foo"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFlags(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFlags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "No arguments", args: nil, want: nil},
+		{name: "Test flags only", args: []string{"-test.v=true", "-test.run=TestRun"}, want: nil},
+		{name: "Command with test flags", args: []string{"-test.v", "run", "deploy", "--name=foo"}, want: []string{"run", "deploy", "--name=foo"}},
+		{name: "Help", args: []string{"--help"}, want: []string{"--help"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandArgs(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commandArgs(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCommand(t *testing.T) {
+	for _, arg := range []string{"run", "list", "history", "help", "-h", "--help"} {
+		if !isCommand(arg) {
+			t.Errorf("isCommand(%q) = false, want true", arg)
+		}
+	}
+	for _, arg := range []string{"--verbose", "deploy/staging", "-v", "lst"} {
+		if isCommand(arg) {
+			t.Errorf("isCommand(%q) = true, want false", arg)
+		}
+	}
+}
+
+func TestRunHeadless(t *testing.T) {
+	t.Setenv("CHARMER_REPLICAS", "5")
+
+	var got []any
+	charms := testCharms(&got)
+	store := &state.Store{}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantArgs []any
+		wantOut  string
+		wantNot  string
+		wantErr  string
+	}{
+		{
+			name:     "Run with flags, env and defaults",
+			args:     []string{"run", "deploy/staging", "--name=foo"},
+			wantCode: exitOK,
+			wantArgs: []any{"foo", 5, false},
+		},
+		{
+			name:     "Flags override env",
+			args:     []string{"run", "deploy/staging", "--name", "foo", "--replicas=1", "--force"},
+			wantCode: exitOK,
+			wantArgs: []any{"foo", 1, true},
+		},
+		{
+			name:     "Missing required parameter",
+			args:     []string{"run", "deploy/staging"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "Invalid parameter value",
+			args:     []string{"run", "deploy/staging", "--name=foo", "--replicas=many"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "Invalid path parameter",
+			args:     []string{"run", "backup", "--dst=foo"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "Unknown flag",
+			args:     []string{"run", "backup", "--dts=/tmp"},
+			wantCode: exitUsage,
+			wantErr:  "--dts",
+		},
+		{
+			name:     "Prompt flags after separator",
+			args:     []string{"run", "deploy/staging", "--name=foo", "--", "--env=prod"},
+			wantCode: exitOK,
+			wantArgs: []any{"foo", 5, false},
+		},
+		{
+			name:     "Unknown charm",
+			args:     []string{"run", "deploy/prod"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "Charm error",
+			args:     []string{"run", "fail"},
+			wantCode: exitError,
+		},
+		{
+			name:     "Confirmation without --yes",
+			args:     []string{"run", "drop"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "Confirmation with --yes=false",
+			args:     []string{"run", "drop", "--yes=false"},
+			wantCode: exitUsage,
+			wantErr:  "requires confirmation",
+		},
+		{
+			name:     "Confirmation with invalid --yes",
+			args:     []string{"run", "drop", "--yes=maybe"},
+			wantCode: exitUsage,
+			wantErr:  "invalid value for --yes",
+		},
+		{
+			name:     "Confirmation with --yes=1",
+			args:     []string{"run", "drop", "--yes=1"},
+			wantCode: exitOK,
+			wantArgs: []any{"dropped"},
+		},
+		{
+			name:     "Confirmation with --yes",
+			args:     []string{"run", "drop", "--yes"},
+			wantCode: exitOK,
+			wantArgs: []any{"dropped"},
+		},
+		{
+			name:     "Charm panic",
+			args:     []string{"run", "panic"},
+			wantCode: exitError,
+		},
+		{
+			name:     "List",
+			args:     []string{"list"},
+			wantCode: exitOK,
+			wantOut:  "deploy/staging",
+		},
+		{
+			name:     "List by tag",
+			args:     []string{"list", "--tag", "#Staging"},
+			wantCode: exitOK,
+			wantOut:  "fail",
+			wantNot:  "drop",
+		},
+		{
+			name:     "List by multiple tags",
+			args:     []string{"list", "--tag=staging,deploy"},
+			wantCode: exitOK,
+			wantOut:  "deploy/staging",
+			wantNot:  "fail",
+		},
+		{
+			name:     "List with unknown flag",
+			args:     []string{"list", "--name=foo"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "Help for charm",
+			args:     []string{"help", "deploy/staging"},
+			wantCode: exitOK,
+			wantOut:  "--replicas",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			var stdout, stderr bytes.Buffer
+
+			code := runHeadless(charms, tt.args, Options{}, store, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("runHeadless() = %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+			if tt.wantArgs != nil && !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Errorf("charm called with %v, want %v", got, tt.wantArgs)
+			}
+			if tt.wantOut != "" && !strings.Contains(stdout.String(), tt.wantOut) {
+				t.Errorf("output %q does not contain %q", stdout.String(), tt.wantOut)
+			}
+			if tt.wantNot != "" && strings.Contains(stdout.String(), tt.wantNot) {
+				t.Errorf("output %q contains %q", stdout.String(), tt.wantNot)
+			}
+			if tt.wantErr != "" && !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("error output %q does not contain %q", stderr.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunHeadless_Last(t *testing.T) {
+	var got []any
+	charms := testCharms(&got)
+	store := &state.Store{}
+	var stdout, stderr bytes.Buffer
+
+	if code := runHeadless(charms, []string{"run", "--last"}, Options{}, store, &stdout, &stderr); code != exitUsage {
+		t.Fatalf("run --last without history = %d, want %d", code, exitUsage)
+	}
+
+	if code := runHeadless(charms, []string{"run", "drop", "--yes"}, Options{}, store, &stdout, &stderr); code != exitOK {
+		t.Fatalf("runHeadless() = %d, want %d (stderr: %s)", code, exitOK, stderr.String())
+	}
+
+	got = nil
+	if code := runHeadless(charms, []string{"run", "--last", "--yes"}, Options{}, store, &stdout, &stderr); code != exitOK {
+		t.Fatalf("run --last = %d, want %d (stderr: %s)", code, exitOK, stderr.String())
+	}
+	if want := []any{"dropped"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("charm called with %v, want %v", got, want)
+	}
+}
+
+func TestRunHeadless_Replay(t *testing.T) {
+	var got []any
+	charms := testCharms(&got)
+	charms["login"] = models.CharmFunc{
+		Path: "login",
+		Params: []models.CharmParam{
+			{Name: "user", Type: models.ParamString},
+			{Name: "token", Type: models.ParamString, Secret: true},
+		},
+		Execute: func(ctx context.Context, args []any) error {
+			got = args
+			return nil
+		},
+	}
+	store := &state.Store{}
+	var stdout, stderr bytes.Buffer
+
+	run := func(args ...string) int {
+		stderr.Reset()
+		return runHeadless(charms, args, Options{}, store, &stdout, &stderr)
+	}
+
+	if code := run("run", "deploy/staging", "--name=web", "--replicas=5"); code != exitOK {
+		t.Fatalf("run = %d, want %d (stderr: %s)", code, exitOK, stderr.String())
+	}
+	if code := run("run", "login", "--user=alice", "--token=hunter2"); code != exitOK {
+		t.Fatalf("run = %d, want %d (stderr: %s)", code, exitOK, stderr.String())
+	}
+
+	entries, err := store.History()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("History() = %+v, %v, want 2 entries", entries, err)
+	}
+	if _, ok := entries[0].Args["token"]; ok || !reflect.DeepEqual(entries[0].Redacted, []string{"token"}) {
+		t.Errorf("secret parameter was recorded: %+v", entries[0])
+	}
+
+	got = nil
+	if code := run("run", "--replay", "1", "--replicas=3"); code != exitOK {
+		t.Fatalf("run --replay = %d, want %d (stderr: %s)", code, exitOK, stderr.String())
+	}
+	if want := []any{"web", 3, false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed charm called with %v, want %v", got, want)
+	}
+
+	if code := run("run", "--replay=2"); code != exitUsage || !strings.Contains(stderr.String(), "--token is secret") {
+		t.Errorf("run --replay without secret = %d (stderr: %s), want %d", code, stderr.String(), exitUsage)
+	}
+	if code := run("run", "--replay=2", "--token=hunter2"); code != exitOK {
+		t.Errorf("run --replay with secret = %d, want %d (stderr: %s)", code, exitOK, stderr.String())
+	}
+	if code := run("run", "--replay", "99"); code != exitUsage {
+		t.Errorf("run --replay of an unknown entry = %d, want %d", code, exitUsage)
+	}
+
+	stdout.Reset()
+	if code := run("history"); code != exitOK || !strings.Contains(stdout.String(), "login") {
+		t.Errorf("history = %d, output %q", code, stdout.String())
+	}
+}
+
+func TestRunHeadless_CrashReport(t *testing.T) {
+	options := Options{CrashReportDir: t.TempDir()}
+
+	var stdout, stderr bytes.Buffer
+	if code := runHeadless(testCharms(new([]any)), []string{"run", "panic"}, options, &state.Store{}, &stdout, &stderr); code != exitError {
+		t.Fatalf("runHeadless() = %d, want %d", code, exitError)
+	}
+
+	reports, err := filepath.Glob(filepath.Join(options.CrashReportDir, "crash-*.log"))
+	if err != nil || len(reports) != 1 {
+		t.Fatalf("expected one crash report, got %v (err: %v)", reports, err)
+	}
+
+	content, err := os.ReadFile(reports[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "Panic: boom") {
+		t.Errorf("crash report does not contain the panic value:\n%s", content)
+	}
+}
+
+func TestWriteCrashReport_SameSecond(t *testing.T) {
+	dir := t.TempDir()
+	panicErr := &PanicError{Charm: "panic", Value: "boom"}
+
+	first, err := writeCrashReport(panicErr, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := writeCrashReport(panicErr, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Errorf("both crash reports were written to %s", first)
+	}
+}
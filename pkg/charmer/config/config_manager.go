@@ -10,6 +10,7 @@ import (
 // to securely store values.
 type Config struct {
 	service string
+	secrets map[string]bool
 }
 
 // New creates a new Config instance with the given service name.
@@ -20,9 +21,23 @@ func New(service string) (*Config, error) {
 	}
 	return &Config{
 		service: service,
+		secrets: make(map[string]bool),
 	}, nil
 }
 
+// DeclareSecrets marks keys as secrets, e.g. passwords and API tokens.
+// SetFromInput masks the input for them.
+func (c *Config) DeclareSecrets(keys ...string) {
+	for _, key := range keys {
+		c.secrets[key] = true
+	}
+}
+
+// IsSecret reports whether the key was declared as a secret.
+func (c *Config) IsSecret(key string) bool {
+	return c.secrets[key]
+}
+
 // Set stores a value in the keyring under the given key.
 func (c *Config) Set(key, value string) error {
 	if key == "" {
@@ -88,7 +103,15 @@ func (c *Config) DeleteAll() error {
 }
 
 // SetFromInput prompts the user for input and stores the value in the keyring.
+// Keys declared as secrets are entered masked, and twice if no value is stored yet.
 func (c *Config) SetFromInput(key string, options console.InputOptions) (string, error) {
+	if c.IsSecret(key) {
+		options.Secret = true
+		if !c.Exists(key) {
+			options.Confirm = true
+		}
+	}
+
 	value, err := console.Input(options)
 	if err != nil {
 		return "", err
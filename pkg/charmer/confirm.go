@@ -0,0 +1,43 @@
+package charmer
+
+import (
+	"fmt"
+	"github.com/ImGajeed76/charmer/pkg/charmer/console"
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+)
+
+// confirm shows the @Confirm gate of a charm and reports whether it may run.
+// Charms without a confirmation are always allowed to run.
+func confirm(charm models.CharmFunc) (bool, error) {
+	if charm.Confirm == nil {
+		return true, nil
+	}
+
+	if charm.Confirm.Phrase == "" {
+		return console.YesNo(console.YesNoOptions{
+			Prompt:     charm.Confirm.Message,
+			DefaultYes: false,
+			YesText:    "Yes",
+			NoText:     "No",
+		})
+	}
+
+	phrase := charm.Confirm.Phrase
+	_, err := console.Input(console.InputOptions{
+		Prompt:    fmt.Sprintf("%s\nType '%s' to confirm:", charm.Confirm.Message, phrase),
+		CharLimit: len(phrase) + 20,
+		Width:     len(phrase) + 2,
+		Required:  true,
+		Validate: func(value string) error {
+			if value != phrase {
+				return fmt.Errorf("type '%s' exactly to confirm", phrase)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		// Cancelling the input declines the confirmation
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,21 @@
+package console
+
+import (
+	"github.com/ImGajeed76/charmer/pkg/charmer/theme"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var warningBannerStyle lipgloss.Style
+
+func applyBannerTheme(t theme.Theme) {
+	warningBannerStyle = lipgloss.NewStyle().
+		Foreground(t.Warning).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Warning).
+		Padding(0, 2)
+}
+
+// WarningBanner renders a highlighted warning message, e.g. to print before a deprecated charm runs
+func WarningBanner(message string) string {
+	return warningBannerStyle.Render("⚠ " + message)
+}
@@ -2,23 +2,31 @@ package console
 
 import (
 	"fmt"
+	"github.com/ImGajeed76/charmer/pkg/charmer/theme"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"strconv"
 	"strings"
 )
 
+// Style definitions, set by applyYesNoTheme
 var (
-	// Style definitions (reusing from original input)
+	selectedStyle   lipgloss.Style
+	unselectedStyle lipgloss.Style
+)
+
+func applyYesNoTheme(t theme.Theme) {
 	selectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#00ADD8")).
-			Bold(true)
+		Foreground(t.Primary).
+		Bold(true)
 
 	unselectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#666666"))
-)
+		Foreground(t.Muted)
+}
 
 // YesNoOptions allows customization of the yes/no input behavior
 type YesNoOptions struct {
+	Name       string // Flag name used to read the value in headless mode (--name=true)
 	Prompt     string
 	DefaultYes bool   // If true, "Yes" is pre-selected
 	YesText    string // Custom text for "Yes" option
@@ -37,12 +45,25 @@ func DefaultYesNoOptions() YesNoOptions {
 
 // YesNo displays a yes/no prompt and returns the user's choice
 func YesNo(opts ...YesNoOptions) (bool, error) {
-	fmt.Print("\033[H\033[2J") // Clear screen
 	options := DefaultYesNoOptions()
 	if len(opts) > 0 {
 		options = opts[0]
 	}
 
+	if !IsInteractive() {
+		value, ok := headlessValue(options.Name)
+		if !ok {
+			return false, notInteractiveError(options.Name, options.Prompt)
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("invalid value for %q: must be true or false", options.Prompt)
+		}
+		return parsed, nil
+	}
+
+	fmt.Print("\033[H\033[2J") // Clear screen
+
 	p := tea.NewProgram(initialYesNoModel(options))
 	m, err := p.Run()
 	if err != nil {
@@ -4,19 +4,29 @@ import (
 	"fmt"
 	constants "github.com/ImGajeed76/charmer/internal"
 	"github.com/charmbracelet/glamour"
+	"hash/fnv"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/76creates/stickers/flexbox"
+	"github.com/ImGajeed76/charmer/pkg/charmer/jobs"
 	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"github.com/ImGajeed76/charmer/pkg/charmer/state"
+	"github.com/ImGajeed76/charmer/pkg/charmer/theme"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// UI Styles configuration
-var styles = struct {
+// selectorStyles holds the styles of the charm selector
+type selectorStyles struct {
 	base         lipgloss.Style
 	card         lipgloss.Style
 	rightCard    lipgloss.Style
@@ -26,54 +36,90 @@ var styles = struct {
 	searchMatch  lipgloss.Style
 	section      lipgloss.Style
 	cursor       lipgloss.Style
+	deprecated   lipgloss.Style
 	title        lipgloss.Style
 	cwd          lipgloss.Style
 	hover        lipgloss.Style
-}{
-	base: lipgloss.NewStyle().Padding(1),
-	card: lipgloss.NewStyle().
-		Padding(2, 3). // Increased padding
-		Width(0).
-		Height(0).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("241")), // Subtle border
-	rightCard: lipgloss.NewStyle().
-		Padding(2, 3).
-		Width(0).
-		Height(0).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color(constants.Theme.PrimaryColor)),
-	topBar: lipgloss.NewStyle().
-		Padding(1).
-		Foreground(lipgloss.Color(constants.Theme.SecondaryColor)).
-		Align(lipgloss.Center),
-	selectedItem: lipgloss.NewStyle().
-		Foreground(lipgloss.Color(constants.Theme.PrimaryColor)).
-		Bold(true).
-		Background(lipgloss.Color("236")), // Subtle highlight background
-	path: lipgloss.NewStyle().
-		Foreground(lipgloss.Color(constants.Theme.SecondaryColor)).
-		Italic(true).
-		Padding(0, 0, 1, 0), // Added bottom padding
-	searchMatch: lipgloss.NewStyle().
-		Underline(true).
-		Background(lipgloss.Color("237")), // Subtle highlight for search matches
-	section: lipgloss.NewStyle().
-		PaddingBottom(1),
-	cursor: lipgloss.NewStyle().
-		Foreground(lipgloss.Color(constants.Theme.PrimaryColor)).
-		Bold(true),
-	title: lipgloss.NewStyle().
-		Foreground(lipgloss.Color(constants.Theme.SecondaryColor)).
-		Bold(true),
-	cwd: lipgloss.NewStyle().
-		Foreground(lipgloss.Color("202")),
-	hover: lipgloss.NewStyle().
-		Foreground(lipgloss.Color("39")).  // Changed to a softer cyan color
-		Background(lipgloss.Color("236")). // Added subtle background
-		Bold(true),
+	tag          lipgloss.Style
+	helpTitle    lipgloss.Style
+	jobsRunning  lipgloss.Style
+	jobError     lipgloss.Style
 }
 
+// UI Styles configuration, set by applySelectorTheme
+var styles selectorStyles
+
+func applySelectorTheme(t theme.Theme) {
+	styles = selectorStyles{
+		base: lipgloss.NewStyle().Padding(1),
+		card: lipgloss.NewStyle().
+			Padding(2, 3). // Increased padding
+			Width(0).
+			Height(0).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(t.Border),
+		rightCard: lipgloss.NewStyle().
+			Padding(2, 3).
+			Width(0).
+			Height(0).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(t.Primary),
+		topBar: lipgloss.NewStyle().
+			Padding(1).
+			Foreground(t.Secondary).
+			Align(lipgloss.Center),
+		selectedItem: lipgloss.NewStyle().
+			Foreground(t.SelectionText).
+			Bold(true).
+			Background(t.Selection),
+		path: lipgloss.NewStyle().
+			Foreground(t.Secondary).
+			Italic(true).
+			Padding(0, 0, 1, 0), // Added bottom padding
+		searchMatch: lipgloss.NewStyle().
+			Underline(true).
+			Background(t.Match),
+		section: lipgloss.NewStyle().
+			PaddingBottom(1),
+		cursor: lipgloss.NewStyle().
+			Foreground(t.Primary).
+			Bold(true),
+		deprecated: lipgloss.NewStyle().
+			Foreground(t.Muted).
+			Faint(true),
+		title: lipgloss.NewStyle().
+			Foreground(t.Secondary).
+			Bold(true),
+		cwd: lipgloss.NewStyle().
+			Foreground(t.Accent),
+		hover: lipgloss.NewStyle().
+			Foreground(t.Hover).
+			Background(t.HoverBackground).
+			Bold(true),
+		tag: lipgloss.NewStyle().
+			Foreground(t.TagText).
+			Padding(0, 1),
+		helpTitle: lipgloss.NewStyle().
+			Foreground(t.Primary).
+			Bold(true).
+			PaddingBottom(1),
+		jobsRunning: lipgloss.NewStyle().
+			Foreground(t.Accent),
+		jobError: lipgloss.NewStyle().
+			Foreground(t.Error),
+	}
+}
+
+// footerHeight is the number of lines below the cards reserved for the short help
+const footerHeight = 1
+
+// Virtual folders pinned at the root, listing the favorite and recently used charms and the run history
+const (
+	FavoritesFolder = "★ Favorites"
+	RecentFolder    = "⟲ Recent"
+	HistoryFolder   = "◷ History"
+)
+
 // CharmSelectorItem represents a selectable item in the charm interface
 type CharmSelectorItem struct {
 	Title       string
@@ -84,6 +130,7 @@ type CharmSelectorItem struct {
 // CharmSelectorModel represents the application state
 type CharmSelectorModel struct {
 	charms      map[string]models.CharmFunc
+	groups      map[string]models.CharmGroup
 	currentPath *string
 	options     []string
 	cursor      int
@@ -91,6 +138,34 @@ type CharmSelectorModel struct {
 	maxEntries  int
 	searchTerm  string
 
+	// Top bar
+	title  string
+	banner string
+
+	// Key bindings and help
+	keys          SelectorKeyMap
+	help          help.Model
+	showHelp      bool
+	searchFocused bool // Only used when the Search binding is enabled
+
+	// Rank and highlights of the current search results by charm path
+	searchMatches map[string]charmMatch
+
+	// Favorites, recently used charms and run history, nil disables the pinned folders
+	store *state.Store
+
+	// Entries listed in the history folder by option, and the entry selected to run again
+	historyEntries map[string]state.HistoryEntry
+	replay         *state.HistoryEntry
+
+	// Background jobs, nil disables them
+	jobs        *jobs.Manager
+	showJobs    bool
+	jobCursor   int
+	jobProgress progress.Model
+	background  bool // The selected charm runs as a background job
+	ticking     bool // A jobs tick is scheduled
+
 	// UI components
 	flexbox   *flexbox.FlexBox
 	topBar    *flexbox.Cell
@@ -116,6 +191,11 @@ type CharmSelectorModel struct {
 	// Hover
 	hoverIndex int
 	isHovering bool
+
+	// Navigation state before a charm was selected, restored by Resume
+	returnPath   string
+	returnCursor int
+	returnOffset int
 }
 
 // NewCharmSelectorModel creates and initializes a new CharmSelectorModel
@@ -141,10 +221,7 @@ func NewCharmSelectorModel(charms map[string]models.CharmFunc, currentPath *stri
 	}
 	fb.AddRows(rows)
 
-	renderer, _ := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(120),
-	)
+	renderer := newMarkdownRenderer(120)
 
 	return &CharmSelectorModel{
 		charms:               charms,
@@ -152,6 +229,9 @@ func NewCharmSelectorModel(charms map[string]models.CharmFunc, currentPath *stri
 		options:              []string{},
 		maxEntries:           5,
 		searchTerm:           "",
+		title:                fmt.Sprintf("Charmer - v%s", constants.Version),
+		keys:                 DefaultSelectorKeyMap(),
+		help:                 help.New(),
 		flexbox:              fb,
 		topBar:               topBar,
 		leftCard:             leftCard,
@@ -162,6 +242,31 @@ func NewCharmSelectorModel(charms map[string]models.CharmFunc, currentPath *stri
 	}
 }
 
+// WithGroups sets the directory metadata used for titles, descriptions and ordering of folders
+func (m *CharmSelectorModel) WithGroups(groups map[string]models.CharmGroup) *CharmSelectorModel {
+	m.groups = groups
+	return m
+}
+
+// WithHeader sets the title shown in the top bar and an optional banner line below it
+func (m *CharmSelectorModel) WithHeader(title, banner string) *CharmSelectorModel {
+	m.title = title
+	m.banner = banner
+	return m
+}
+
+// WithKeyMap sets the key bindings of the selector
+func (m *CharmSelectorModel) WithKeyMap(keys SelectorKeyMap) *CharmSelectorModel {
+	m.keys = keys
+	return m
+}
+
+// WithState sets the store of favorites, recently used charms and run history shown in the pinned folders
+func (m *CharmSelectorModel) WithState(store *state.Store) *CharmSelectorModel {
+	m.store = store
+	return m
+}
+
 func (m *CharmSelectorModel) getCacheKey(option string) string {
 	return *m.currentPath + option
 }
@@ -169,44 +274,235 @@ func (m *CharmSelectorModel) getCacheKey(option string) string {
 func (m *CharmSelectorModel) Init() tea.Cmd {
 	m.updateOptions()
 
-	// Update the TopBar to include the current working directory in a new line
+	// Update the TopBar to include the banner and the current working directory in new lines
+	header := styles.title.Render(m.title)
+	if m.banner != "" {
+		header += "\n" + m.banner
+	}
 	cwd, _ := os.Getwd()
-	title := styles.title.Render(fmt.Sprintf("Charmer - v%s", constants.Version))
-	cwd = styles.cwd.Render(cwd)
-	m.topBar.SetContent(title + "\n" + cwd)
+	m.topBar.SetContent(header + "\n" + styles.cwd.Render(cwd))
 
-	return nil
+	// Ticks of the previous run of the program are gone
+	m.ticking = false
+	return m.scheduleJobsTick()
 }
 
 // updateOptions filters and updates available options based on the current path and search term
 func (m *CharmSelectorModel) updateOptions() {
-	if m.searchTerm != "" {
+	// Highlights only belong to the results of the current search, virtual folders list the same paths
+	if m.searchTerm == "" {
+		m.searchMatches = nil
+	}
+
+	switch {
+	case m.searchTerm != "":
 		m.updateSearchOptions()
-	} else {
-		m.options = GetAvailablePathOptions(m.charms, *m.currentPath)
+	case m.inHistoryFolder():
+		m.options = append([]string{".."}, m.historyOptions()...)
+	case m.inVirtualFolder():
+		m.options = append([]string{".."}, m.virtualFolderCharms(strings.TrimSuffix(*m.currentPath, "/"))...)
+	default:
+		m.options = GetSortedPathOptions(m.charms, m.groups, *m.currentPath)
+		if *m.currentPath == "" {
+			m.options = append(m.pinnedFolders(), m.options...)
+		}
+	}
+}
+
+// inVirtualFolder reports whether the favorites or recent folder is open
+func (m *CharmSelectorModel) inVirtualFolder() bool {
+	return *m.currentPath == FavoritesFolder+"/" || *m.currentPath == RecentFolder+"/"
+}
+
+// virtualFolderCharms returns the paths of the visible charms listed in a virtual folder
+func (m *CharmSelectorModel) virtualFolderCharms(folder string) []string {
+	if m.store == nil {
+		return nil
+	}
+
+	paths := m.store.Recent
+	if folder == FavoritesFolder {
+		paths = m.store.Favorites
+	}
+
+	// The store may reference charms that were removed or hidden since
+	visible := make([]string, 0, len(paths))
+	for _, charmPath := range paths {
+		if charm, ok := m.charms[charmPath]; ok && !charm.Hidden {
+			visible = append(visible, charmPath)
+		}
+	}
+	return visible
+}
+
+// pinnedFolders returns the virtual folders that contain at least one entry
+func (m *CharmSelectorModel) pinnedFolders() []string {
+	var folders []string
+	for _, folder := range []string{FavoritesFolder, RecentFolder} {
+		if len(m.virtualFolderCharms(folder)) > 0 {
+			folders = append(folders, folder)
+		}
+	}
+	if len(m.historyOptions()) > 0 {
+		folders = append(folders, HistoryFolder)
+	}
+	return folders
+}
+
+// inHistoryFolder reports whether the run history is open
+func (m *CharmSelectorModel) inHistoryFolder() bool {
+	return *m.currentPath == HistoryFolder+"/"
+}
+
+// historyOptions loads the run history and returns an option per entry, most recent first
+func (m *CharmSelectorModel) historyOptions() []string {
+	m.historyEntries = nil
+	if m.store == nil {
+		return nil
+	}
+	entries, err := m.store.History()
+	if err != nil {
+		return nil
+	}
+
+	options := make([]string, 0, len(entries))
+	m.historyEntries = make(map[string]state.HistoryEntry, len(entries))
+	for _, entry := range entries {
+		option := fmt.Sprintf("#%d", entry.ID)
+		options = append(options, option)
+		m.historyEntries[option] = entry
 	}
+	return options
+}
+
+// historyEntry returns the history entry an option in the history folder refers to
+func (m *CharmSelectorModel) historyEntry(option string) (state.HistoryEntry, bool) {
+	if m.searchTerm != "" || !m.inHistoryFolder() {
+		return state.HistoryEntry{}, false
+	}
+	entry, ok := m.historyEntries[option]
+	return entry, ok
+}
+
+// Replay returns the history entry that was selected to run again, if the last selection was one.
+// The selected charm path is set to the charm of the entry.
+func (m *CharmSelectorModel) Replay() (state.HistoryEntry, bool) {
+	if m.replay == nil {
+		return state.HistoryEntry{}, false
+	}
+	return *m.replay, true
+}
+
+// charmForOption returns the charm an option refers to. Search results and
+// virtual folders list full charm paths, everything else is relative to the current path.
+func (m *CharmSelectorModel) charmForOption(option string) (models.CharmFunc, bool) {
+	if m.searchTerm != "" || m.inVirtualFolder() {
+		charm, ok := m.charms[option]
+		return charm, ok
+	}
+	charm, ok := m.charms[*m.currentPath+option]
+	return charm, ok
+}
+
+// Weights of the fields searched, a match in the title ranks above one in the path or description
+const (
+	weightTitle       = 3
+	weightPath        = 2
+	weightDescription = 1
+)
+
+// charmMatch holds the rank of a charm in the search results and the matched characters to highlight
+type charmMatch struct {
+	score        int
+	titleMatches []int
+	pathMatches  []int
 }
 
-// updateSearchOptions updates options based on the current search term
+// updateSearchOptions updates options based on the current search term, best matches first
 func (m *CharmSelectorModel) updateSearchOptions() {
 	filtered := make([]string, 0)
-	searchLower := strings.ToLower(m.searchTerm)
+	text, tags := parseSearch(m.searchTerm)
+	terms := strings.Fields(text)
+	m.searchMatches = make(map[string]charmMatch)
 
 	for path, charm := range m.charms {
-		if m.matchesSearch(path, charm, searchLower) {
+		if charm.Hidden || !hasTagPrefixes(charm, tags) {
+			continue
+		}
+		if match, ok := matchCharm(path, charm, terms); ok {
+			m.searchMatches[path] = match
 			filtered = append(filtered, path)
 		}
 	}
 
-	sort.Strings(filtered)
+	sort.Slice(filtered, func(i, j int) bool {
+		scoreI := m.searchMatches[filtered[i]].score
+		scoreJ := m.searchMatches[filtered[j]].score
+		if scoreI != scoreJ {
+			return scoreI > scoreJ
+		}
+		return filtered[i] < filtered[j]
+	})
 	m.options = filtered
 }
 
-// matchesSearch checks if a charm matches the search criteria
-func (m *CharmSelectorModel) matchesSearch(path string, charm models.CharmFunc, searchTerm string) bool {
-	return strings.Contains(strings.ToLower(path), searchTerm) ||
-		strings.Contains(strings.ToLower(charm.Title), searchTerm) ||
-		strings.Contains(strings.ToLower(charm.Description), searchTerm)
+// hasTagPrefixes checks that every tag filter matches one of the charm's tags by prefix,
+// so results narrow down while the tag is still being typed
+func hasTagPrefixes(charm models.CharmFunc, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(charm.Tags, func(t string) bool { return strings.HasPrefix(t, tag) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchCharm fuzzy matches every search term against the title, path, description and doc of a charm.
+// Each term is scored by its best weighted field and the charm scores the sum over all terms.
+func matchCharm(path string, charm models.CharmFunc, terms []string) (charmMatch, bool) {
+	var match charmMatch
+
+	for _, term := range terms {
+		best := noMatch
+
+		if score, positions, ok := fuzzyMatch(term, charm.Title); ok {
+			best = max(best, score*weightTitle)
+			match.titleMatches = append(match.titleMatches, positions...)
+		}
+		if score, positions, ok := fuzzyMatch(term, path); ok {
+			best = max(best, score*weightPath)
+			match.pathMatches = append(match.pathMatches, positions...)
+		}
+		if score, _, ok := fuzzyMatch(term, charm.Description); ok {
+			best = max(best, score*weightDescription)
+		}
+		if score, _, ok := fuzzyMatch(term, charm.Doc); ok {
+			best = max(best, score*weightDescription)
+		}
+
+		if best == noMatch {
+			return charmMatch{}, false
+		}
+		match.score += best
+	}
+
+	return match, true
+}
+
+// parseSearch splits a search term into its text and tag filters.
+// Words starting with # are tag filters, e.g. "#prod deploy" searches for "deploy" in charms tagged prod.
+func parseSearch(term string) (string, []string) {
+	var words, tags []string
+	for _, word := range strings.Fields(term) {
+		if strings.HasPrefix(word, "#") {
+			if tag := strings.ToLower(strings.TrimPrefix(word, "#")); tag != "" {
+				tags = append(tags, tag)
+			}
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), tags
 }
 
 // Update handles UI state updates based on user input
@@ -223,6 +519,9 @@ func (m *CharmSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleKeyPress(msg)
 	case tea.MouseMsg:
 		return m.handleMouseMsg(msg)
+	case jobsTickMsg:
+		m.ticking = false
+		return m, m.scheduleJobsTick()
 	}
 
 	return m, nil
@@ -231,7 +530,8 @@ func (m *CharmSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // handleWindowSize updates the UI layout based on window size
 func (m *CharmSelectorModel) handleWindowSize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 	m.flexbox.SetWidth(msg.Width)
-	m.flexbox.SetHeight(msg.Height)
+	m.flexbox.SetHeight(msg.Height - footerHeight)
+	m.help.Width = msg.Width - 2
 	m.flexbox.ForceRecalculate()
 	m.maxEntries = m.leftCard.GetHeight() - 7
 
@@ -241,10 +541,7 @@ func (m *CharmSelectorModel) handleWindowSize(msg tea.WindowSizeMsg) (tea.Model,
 	m.descriptionMaxHeight = m.rightCard.GetHeight() - (totalPadding + borderSpace + titleSpace)
 	m.descriptionMaxWidth = m.rightCard.GetWidth() - 10
 
-	m.markdownRenderer, _ = glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(m.descriptionMaxWidth),
-	)
+	m.markdownRenderer = newMarkdownRenderer(m.descriptionMaxWidth)
 
 	m.cleanup()
 
@@ -255,34 +552,61 @@ func (m *CharmSelectorModel) handleWindowSize(msg tea.WindowSizeMsg) (tea.Model,
 
 // handleKeyPress processes keyboard input
 func (m *CharmSelectorModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q":
+	// While the search is focused, printable keys belong to the search term
+	if m.searchFocused && isSearchKey(msg) {
+		return m.handleSearchInput(msg)
+	}
+	if m.showJobs {
+		return m.handleJobsKey(msg)
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Quit):
 		*m.currentPath = "" // reset so no function gets called
 		return m, tea.Quit
-	case "up":
+	case key.Matches(msg, m.keys.Help):
+		m.showHelp = !m.showHelp
+	case m.showHelp && msg.Type == tea.KeyEsc:
+		m.showHelp = false
+	case key.Matches(msg, m.keys.Up):
 		if m.mouseX < m.leftCard.GetWidth() {
 			m.navigateUp()
 		} else {
 			m.scrollDescriptionUp()
 		}
-	case "down":
+	case key.Matches(msg, m.keys.Down):
 		if m.mouseX < m.leftCard.GetWidth() {
 			m.navigateDown()
 		} else {
 			m.scrollDescriptionDown()
 		}
-	case "enter":
+	case key.Matches(msg, m.keys.Select):
 		return m.handleEnter()
-	case "backspace":
+	case key.Matches(msg, m.keys.Back):
 		return m.handleBackspace()
-	case "esc":
+	case key.Matches(msg, m.keys.Clear):
 		return m.handleEscape()
-	default:
+	case key.Matches(msg, m.keys.Favorite):
+		m.toggleFavorite()
+	case key.Matches(msg, m.keys.Search):
+		m.searchFocused = true
+	case m.jobs != nil && key.Matches(msg, m.keys.Jobs):
+		m.showJobs = true
+		m.jobCursor = 0
+		return m, m.scheduleJobsTick()
+	case m.jobs != nil && key.Matches(msg, m.keys.Background):
+		return m.handleBackground()
+	case !m.keys.Search.Enabled():
 		return m.handleSearchInput(msg)
 	}
 	return m, nil
 }
 
+// isSearchKey reports whether the key can be typed into the search
+func isSearchKey(msg tea.KeyMsg) bool {
+	return (len(msg.String()) == 1 && msg.Type == tea.KeyRunes) || msg.Type == tea.KeySpace
+}
+
 func (m *CharmSelectorModel) handleMouseMsg(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	m.mouseX = msg.X
 	m.mouseY = msg.Y
@@ -318,7 +642,7 @@ func (m *CharmSelectorModel) handleMouseMsg(msg tea.MouseMsg) (tea.Model, tea.Cm
 
 	if m.mouseX < m.leftCard.GetWidth() {
 		relativeY := msg.Y - m.topBar.GetHeight() - 1
-		if m.searchTerm != "" {
+		if m.searchVisible() {
 			relativeY--
 		}
 		relativeY -= 6
@@ -379,10 +703,27 @@ func (m *CharmSelectorModel) handleEnter() (tea.Model, tea.Cmd) {
 	selectedOption := m.options[m.cursor+m.offset]
 	oldPath := *m.currentPath
 
+	m.returnPath = oldPath
+	m.returnCursor = m.cursor
+	m.returnOffset = m.offset
+	m.replay = nil
+
+	// Selecting a history entry runs its charm again with the recorded arguments
+	if entry, ok := m.historyEntry(selectedOption); ok {
+		if _, exists := m.charms[entry.Charm]; !exists {
+			return m, nil
+		}
+		m.replay = &entry
+		*m.currentPath = entry.Charm
+		return m, tea.Quit
+	}
+
 	if m.searchTerm != "" {
 		*m.currentPath = selectedOption
 	} else if selectedOption == ".." {
 		return m.handleBackspace()
+	} else if m.inVirtualFolder() {
+		*m.currentPath = selectedOption
 	} else {
 		*m.currentPath = filepath.Join(*m.currentPath, selectedOption) + "/"
 		m.updateOptions()
@@ -403,6 +744,44 @@ func (m *CharmSelectorModel) handleEnter() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// toggleFavorite adds the selected charm to the favorites or removes it
+func (m *CharmSelectorModel) toggleFavorite() {
+	if m.store == nil || len(m.options) == 0 {
+		return
+	}
+
+	index := m.cursor + m.offset
+	option := m.options[index]
+	charm, ok := m.charmForOption(option)
+	if !ok {
+		return
+	}
+
+	m.store.ToggleFavorite(charm.Path)
+	_ = m.store.Save()
+
+	// The favorites folder may have appeared or lost an entry, keep the cursor on the same option
+	m.updateOptions()
+	if i := slices.Index(m.options, option); i >= 0 {
+		index = i
+	}
+	m.moveCursorTo(min(index, len(m.options)-1))
+	m.lastSelectedOption = ""
+	m.prerenderDescription()
+}
+
+// moveCursorTo selects the option at index, scrolling as little as needed
+func (m *CharmSelectorModel) moveCursorTo(index int) {
+	m.resetNavigationState()
+	if index < 0 {
+		return
+	}
+	if index >= m.maxEntries {
+		m.offset = index - m.maxEntries + 1
+	}
+	m.cursor = index - m.offset
+}
+
 func (m *CharmSelectorModel) handleBackspace() (tea.Model, tea.Cmd) {
 	if m.searchTerm != "" {
 		m.searchTerm = m.searchTerm[:len(m.searchTerm)-1]
@@ -416,8 +795,9 @@ func (m *CharmSelectorModel) handleBackspace() (tea.Model, tea.Cmd) {
 }
 
 func (m *CharmSelectorModel) handleEscape() (tea.Model, tea.Cmd) {
-	if m.searchTerm != "" {
+	if m.searchVisible() {
 		m.searchTerm = ""
+		m.searchFocused = false
 		m.updateOptions()
 	} else {
 		m.navigateBack()
@@ -428,7 +808,7 @@ func (m *CharmSelectorModel) handleEscape() (tea.Model, tea.Cmd) {
 }
 
 func (m *CharmSelectorModel) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	if len(msg.String()) == 1 && msg.Type == tea.KeyRunes {
+	if isSearchKey(msg) && (msg.Type != tea.KeySpace || m.searchTerm != "") {
 		m.searchTerm += msg.String()
 		m.updateOptions()
 		m.resetNavigationState()
@@ -469,7 +849,7 @@ func (m *CharmSelectorModel) View() string {
 		styles.path.Render("Current Path: " + *m.currentPath))
 	leftCardContent.WriteString(pathSection)
 
-	if m.searchTerm != "" {
+	if m.searchVisible() {
 		searchSection := styles.section.Render(
 			fmt.Sprintf("Search: %s", m.searchTerm))
 		leftCardContent.WriteString(searchSection)
@@ -478,7 +858,36 @@ func (m *CharmSelectorModel) View() string {
 
 	m.renderNavigationOptions(&leftCardContent)
 	m.leftCard.SetContent(leftCardContent.String())
-	return m.flexbox.Render()
+
+	switch {
+	case m.showHelp:
+		m.rightCard.SetContent(m.helpView())
+	case m.showJobs:
+		m.rightCard.SetContent(m.jobsView())
+	}
+
+	footer := " " + m.help.ShortHelpView(m.keyMap().ShortHelp())
+	if running := m.runningJobs(); running > 0 {
+		footer += styles.jobsRunning.Render(fmt.Sprintf(" • %d running", running))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.flexbox.Render(), footer)
+}
+
+// helpView renders all key bindings in a single column, so they fit into the description card
+func (m *CharmSelectorModel) helpView() string {
+	var bindings []key.Binding
+	for _, group := range m.keyMap().FullHelp() {
+		bindings = append(bindings, group...)
+	}
+
+	full := m.help
+	full.Width = 0
+	return styles.helpTitle.Render("Keyboard Shortcuts") + "\n" + full.FullHelpView([][]key.Binding{bindings})
+}
+
+// searchVisible reports whether the search line is shown
+func (m *CharmSelectorModel) searchVisible() bool {
+	return m.searchTerm != "" || m.searchFocused
 }
 
 func (m *CharmSelectorModel) prerenderDescription() {
@@ -504,9 +913,10 @@ func (m *CharmSelectorModel) prerenderDescription() {
 		return
 	}
 
-	// Only render if it's actually a charm
-	if charm, ok := m.charms[*m.currentPath+selectedOption]; ok {
-		rendered, err := m.markdownRenderer.Render(charm.Description)
+	// Only render if it's actually a charm or a group with a description
+	description, ok := m.optionDescription(selectedOption)
+	if ok {
+		rendered, err := m.markdownRenderer.Render(description)
 		if err != nil {
 			m.currentDescription = "Error rendering description"
 			m.descriptionLines = []string{"Error rendering description"}
@@ -593,44 +1003,29 @@ func (m *CharmSelectorModel) renderOption(content *strings.Builder, index int, o
 	cursor := " "
 	if index == m.cursor+m.offset {
 		cursor = ">"
-		if _, ok := m.charms[*m.currentPath+option]; ok {
+		if _, ok := m.optionDescription(option); ok {
 			m.updateDescriptionView()
 		} else {
 			m.rightCard.SetContent("")
 		}
 	}
 
-	if charm, ok := m.charms[*m.currentPath+option]; ok {
+	if entry, ok := m.historyEntry(option); ok {
+		m.renderHistoryOption(content, index, option, cursor, entry)
+	} else if charm, ok := m.charmForOption(option); ok {
 		m.renderCharmOption(content, index, option, cursor, charm)
 	} else {
 		m.renderPathOption(content, index, option, cursor)
 	}
 }
 
-// renderCharmOption renders a charm option with title and path
+// renderCharmOption renders a charm option with title, path and tags
 func (m *CharmSelectorModel) renderCharmOption(content *strings.Builder, index int, option, cursor string, charm models.CharmFunc) {
 	var optionText string
-	if m.searchTerm != "" {
-		title := charm.Title
-		path := option
-
-		// Highlight search matches in title
-		if strings.Contains(strings.ToLower(title), strings.ToLower(m.searchTerm)) {
-			idx := strings.Index(strings.ToLower(title), strings.ToLower(m.searchTerm))
-			matchLen := len(m.searchTerm)
-			title = title[:idx] +
-				styles.searchMatch.Render(title[idx:idx+matchLen]) +
-				title[idx+matchLen:]
-		}
-
-		// Highlight search matches in path
-		if strings.Contains(strings.ToLower(path), strings.ToLower(m.searchTerm)) {
-			idx := strings.Index(strings.ToLower(path), strings.ToLower(m.searchTerm))
-			matchLen := len(m.searchTerm)
-			path = path[:idx] +
-				styles.searchMatch.Render(path[idx:idx+matchLen]) +
-				path[idx+matchLen:]
-		}
+	if m.searchTerm != "" || m.inVirtualFolder() {
+		match := m.searchMatches[option]
+		title := highlightMatches(charm.Title, match.titleMatches)
+		path := highlightMatches(option, match.pathMatches)
 
 		optionText = fmt.Sprintf("%s %s (%s)",
 			styles.cursor.Render(cursor),
@@ -644,19 +1039,146 @@ func (m *CharmSelectorModel) renderCharmOption(content *strings.Builder, index i
 			segment)
 	}
 
+	if charm.Deprecated != "" {
+		optionText += " (deprecated)"
+	}
+	if m.store != nil && m.store.IsFavorite(charm.Path) {
+		optionText += " ★"
+	}
+
 	switch {
 	case m.isHovering && index == m.hoverIndex:
 		optionText = styles.hover.Render(optionText)
 	case index == m.cursor+m.offset:
 		optionText = styles.selectedItem.Render(optionText)
+	case charm.Deprecated != "":
+		optionText = styles.deprecated.Render(optionText)
+	}
+
+	// Chips are added after the row style so their colors are not overridden
+	content.WriteString(optionText + renderTags(charm.Tags) + "\n")
+}
+
+// renderHistoryOption renders a run history entry with its charm, start time and status
+func (m *CharmSelectorModel) renderHistoryOption(content *strings.Builder, index int, option, cursor string, entry state.HistoryEntry) {
+	title := entry.Charm
+	charm, exists := m.charms[entry.Charm]
+	if exists {
+		title = charm.Title
+	}
+
+	optionText := fmt.Sprintf("%s %s %s %s (%s) · %s",
+		styles.cursor.Render(cursor),
+		historyStatusIcon(entry.Status),
+		option,
+		title,
+		entry.Charm,
+		entry.Start.Local().Format("2006-01-02 15:04"))
+
+	switch {
+	case m.isHovering && index == m.hoverIndex:
+		optionText = styles.hover.Render(optionText)
+	case index == m.cursor+m.offset:
+		optionText = styles.selectedItem.Render(optionText)
+	case !exists:
+		// The charm was removed since, it cannot be run again
+		optionText = styles.deprecated.Render(optionText)
 	}
 
 	content.WriteString(optionText + "\n")
 }
 
+// historyStatusIcon returns the icon shown for the status of a history entry
+func historyStatusIcon(status string) string {
+	switch status {
+	case "ok":
+		return "✓"
+	case "cancelled":
+		return "⊘"
+	}
+	return "✗"
+}
+
+// historyDescription renders the details of a history entry as markdown
+func (m *CharmSelectorModel) historyDescription(entry state.HistoryEntry) string {
+	var description strings.Builder
+	_, _ = fmt.Fprintf(&description, "# Run #%d\n\n", entry.ID)
+	_, _ = fmt.Fprintf(&description, "- **Charm:** `%s`\n", entry.Charm)
+	_, _ = fmt.Fprintf(&description, "- **Started:** %s\n", entry.Start.Local().Format("2006-01-02 15:04:05"))
+	_, _ = fmt.Fprintf(&description, "- **Duration:** %s\n", entry.Duration().Round(time.Millisecond))
+	_, _ = fmt.Fprintf(&description, "- **Status:** %s %s\n", historyStatusIcon(entry.Status), entry.Status)
+	if entry.Error != "" {
+		_, _ = fmt.Fprintf(&description, "\n> %s\n", entry.Error)
+	}
+
+	if len(entry.Args) > 0 || len(entry.Redacted) > 0 {
+		description.WriteString("\n| Parameter | Value |\n|---|---|\n")
+		names := slices.Sorted(maps.Keys(entry.Args))
+		for _, name := range names {
+			_, _ = fmt.Fprintf(&description, "| %s | `%s` |\n", name, entry.Args[name])
+		}
+		for _, name := range entry.Redacted {
+			_, _ = fmt.Fprintf(&description, "| %s | *secret, asked again* |\n", name)
+		}
+	}
+
+	if _, ok := m.charms[entry.Charm]; ok {
+		description.WriteString("\nPress enter to run it again with the same arguments.\n")
+	} else {
+		description.WriteString("\nThis charm no longer exists.\n")
+	}
+	return description.String()
+}
+
+// newMarkdownRenderer creates the description renderer with the glamour style of the current theme
+func newMarkdownRenderer(wordWrap int) *glamour.TermRenderer {
+	style := currentTheme.Markdown
+	if style == "" {
+		style = "auto"
+	}
+
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle(style),
+		glamour.WithWordWrap(wordWrap),
+	)
+	if err != nil {
+		// Unknown style names fall back to detecting the style from the terminal
+		renderer, _ = glamour.NewTermRenderer(
+			glamour.WithAutoStyle(),
+			glamour.WithWordWrap(wordWrap),
+		)
+	}
+	return renderer
+}
+
+// renderTags renders tags as colored chips
+func renderTags(tags []string) string {
+	var chips strings.Builder
+	for _, tag := range tags {
+		chips.WriteString(" ")
+		chips.WriteString(styles.tag.Background(tagColor(tag)).Render(tag))
+	}
+	return chips.String()
+}
+
+// tagColor picks a stable color for a tag from the tag palette of the current theme
+func tagColor(tag string) lipgloss.TerminalColor {
+	colors := currentTheme.Tags
+	if len(colors) == 0 {
+		return currentTheme.Primary
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(tag))
+	return colors[h.Sum32()%uint32(len(colors))]
+}
+
 // renderPathOption renders a path option
 func (m *CharmSelectorModel) renderPathOption(content *strings.Builder, index int, option, cursor string) {
 	optionText := cursor + " " + option
+	if group, ok := m.groups[*m.currentPath+option]; ok {
+		optionText = fmt.Sprintf("%s %s (%s)", cursor, groupLabel(group), option)
+	}
 
 	switch {
 	case m.isHovering && index == m.hoverIndex:
@@ -678,11 +1200,60 @@ func (m *CharmSelectorModel) getPathSegment(path string) string {
 	return segment
 }
 
+// optionDescription returns the markdown description of a charm or group option
+func (m *CharmSelectorModel) optionDescription(option string) (string, bool) {
+	if entry, ok := m.historyEntry(option); ok {
+		return m.historyDescription(entry), true
+	}
+	if charm, ok := m.charmForOption(option); ok {
+		if charm.Deprecated != "" {
+			return fmt.Sprintf("> ⚠ **Deprecated:** %s\n\n%s", charm.Deprecated, charm.Description), true
+		}
+		return charm.Description, true
+	}
+	if group, ok := m.groups[*m.currentPath+option]; ok && group.Description != "" {
+		return group.Description, true
+	}
+	return "", false
+}
+
+// groupLabel renders the title of a group with its icon
+func groupLabel(group models.CharmGroup) string {
+	if group.Icon != "" {
+		return group.Icon + " " + group.Title
+	}
+	return group.Title
+}
+
 // GetAvailablePathOptions returns a sorted list of available path options
 func GetAvailablePathOptions(charms map[string]models.CharmFunc, currentPath string) []string {
+	return GetSortedPathOptions(charms, nil, currentPath)
+}
+
+// optionOrder returns the sort order and title of a charm or group.
+// The title falls back to the last path segment.
+func optionOrder(charms map[string]models.CharmFunc, groups map[string]models.CharmGroup, path string) (int, string) {
+	order, title := 0, ""
+	if charm, ok := charms[path]; ok {
+		order, title = charm.Order, charm.Title
+	} else if group, ok := groups[path]; ok {
+		order, title = group.Order, group.Title
+	}
+	if title == "" {
+		title = path[strings.LastIndex(path, "/")+1:]
+	}
+	return order, title
+}
+
+// GetSortedPathOptions returns the visible path options sorted by their order, then by title.
+// An order of 0 means no @Order was given, those options come after the ordered ones.
+func GetSortedPathOptions(charms map[string]models.CharmFunc, groups map[string]models.CharmGroup, currentPath string) []string {
 	uniqueOptions := make(map[string]bool)
 
-	for path := range charms {
+	for path, charm := range charms {
+		if charm.Hidden {
+			continue
+		}
 		if strings.HasPrefix(path, currentPath) {
 			remaining := strings.TrimPrefix(path, currentPath)
 			remaining = strings.TrimPrefix(remaining, "/")
@@ -698,7 +1269,20 @@ func GetAvailablePathOptions(charms map[string]models.CharmFunc, currentPath str
 	for option := range uniqueOptions {
 		options = append(options, option)
 	}
-	sort.Strings(options)
+	sort.Slice(options, func(i, j int) bool {
+		orderI, titleI := optionOrder(charms, groups, currentPath+options[i])
+		orderJ, titleJ := optionOrder(charms, groups, currentPath+options[j])
+		if (orderI == 0) != (orderJ == 0) {
+			return orderJ == 0
+		}
+		if orderI != orderJ {
+			return orderI < orderJ
+		}
+		if titleI != titleJ {
+			return titleI < titleJ
+		}
+		return options[i] < options[j]
+	})
 
 	if currentPath != "" {
 		options = append([]string{".."}, options...)
@@ -707,6 +1291,28 @@ func GetAvailablePathOptions(charms map[string]models.CharmFunc, currentPath str
 	return options
 }
 
+// Resume restores the path, search term and cursor from before the last charm was selected,
+// so the model can be run again after the charm finished
+func (m *CharmSelectorModel) Resume() {
+	*m.currentPath = m.returnPath
+	m.replay = nil
+	m.background = false
+	m.updateOptions()
+
+	m.cursor = m.returnCursor
+	m.offset = m.returnOffset
+	if m.cursor+m.offset >= len(m.options) {
+		m.resetNavigationState()
+	}
+
+	m.mouseDown = false
+	m.isHovering = false
+	m.descriptionOffset = 0
+	m.lastSelectedOption = ""
+	m.cleanup()
+	m.prerenderDescription()
+}
+
 func (m *CharmSelectorModel) cleanup() {
 	// Clear caches when path changes or on exit
 	m.descriptionCache = make(map[string]string)
@@ -0,0 +1,236 @@
+package console
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"github.com/ImGajeed76/charmer/pkg/charmer/state"
+)
+
+func TestGetSortedPathOptions(t *testing.T) {
+	charms := map[string]models.CharmFunc{
+		"b/Run":       {Path: "b/Run"},
+		"a/Run":       {Path: "a/Run"},
+		"c/Run":       {Path: "c/Run"},
+		"Zeta":        {Path: "Zeta", Order: -1},
+		"Alpha":       {Path: "Alpha"},
+		"Secret":      {Path: "Secret", Hidden: true},
+		"hidden/Only": {Path: "hidden/Only", Hidden: true},
+		"a/Second":    {Path: "a/Second", Order: 2},
+		"a/First":     {Path: "a/First", Order: 1},
+		"d/Beta":      {Path: "d/Beta", Title: "Beta"},
+		"d/Alpha":     {Path: "d/Alpha", Title: "Zulu"},
+	}
+	groups := map[string]models.CharmGroup{
+		"c": {Path: "c", Order: 1},
+	}
+
+	tests := []struct {
+		name        string
+		currentPath string
+		want        []string
+	}{
+		{
+			name:        "Root sorted by order, then alphabetically",
+			currentPath: "",
+			want:        []string{"Zeta", "c", "Alpha", "a", "b", "d"},
+		},
+		{
+			name:        "Unordered charms after ordered ones",
+			currentPath: "a/",
+			want:        []string{"..", "First", "Second", "Run"},
+		},
+		{
+			name:        "Sorted by title",
+			currentPath: "d/",
+			want:        []string{"..", "Beta", "Alpha"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetSortedPathOptions(charms, groups, tt.currentPath)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetSortedPathOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchOptions_Tags(t *testing.T) {
+	charms := map[string]models.CharmFunc{
+		"deploy/Prod":    {Path: "deploy/Prod", Title: "Deploy", Tags: []string{"prod", "slow"}},
+		"deploy/Staging": {Path: "deploy/Staging", Title: "Deploy", Tags: []string{"staging"}},
+		"db/Backup":      {Path: "db/Backup", Title: "Backup", Tags: []string{"prod", "read-only"}},
+		"db/Secret":      {Path: "db/Secret", Title: "Deploy", Tags: []string{"prod"}, Hidden: true},
+	}
+
+	tests := []struct {
+		name   string
+		search string
+		want   []string
+	}{
+		{
+			name:   "Text only",
+			search: "deploy",
+			want:   []string{"deploy/Prod", "deploy/Staging"},
+		},
+		{
+			name:   "Tag only",
+			search: "#prod",
+			want:   []string{"db/Backup", "deploy/Prod"},
+		},
+		{
+			name:   "Tag and text",
+			search: "#prod deploy",
+			want:   []string{"deploy/Prod"},
+		},
+		{
+			name:   "Multiple tags",
+			search: "#PROD #read",
+			want:   []string{"db/Backup"},
+		},
+		{
+			name:   "Unknown tag",
+			search: "#dev",
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentPath := ""
+			m := NewCharmSelectorModel(charms, &currentPath)
+			m.searchTerm = tt.search
+			m.updateOptions()
+			if !reflect.DeepEqual(m.options, tt.want) {
+				t.Errorf("options for %q = %v, want %v", tt.search, m.options, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchOptions_Ranking(t *testing.T) {
+	charms := map[string]models.CharmFunc{
+		"a/Notes":  {Path: "a/Notes", Title: "Notes", Description: "Write a backup of all notes"},
+		"b/Backup": {Path: "b/Backup", Title: "Backup"},
+		"backup/X": {Path: "backup/X", Title: "Restore"},
+		"c/Doc":    {Path: "c/Doc", Title: "Cleanup", Doc: "Cleanup godoc\n@Charm\nRemoves old bkp files"},
+		"d/Other":  {Path: "d/Other", Title: "Other"},
+	}
+
+	currentPath := ""
+	m := NewCharmSelectorModel(charms, &currentPath)
+	m.searchTerm = "backup"
+	m.updateOptions()
+
+	want := []string{"b/Backup", "backup/X", "a/Notes"}
+	if !reflect.DeepEqual(m.options, want) {
+		t.Errorf("options = %v, want %v", m.options, want)
+	}
+
+	m.searchTerm = "bkp"
+	m.updateOptions()
+	if !slices.Contains(m.options, "c/Doc") {
+		t.Errorf("options = %v, want the doc match c/Doc", m.options)
+	}
+}
+
+func TestPinnedFolders(t *testing.T) {
+	charms := map[string]models.CharmFunc{
+		"db/Backup": {Path: "db/Backup", Title: "Backup"},
+		"db/Secret": {Path: "db/Secret", Title: "Secret", Hidden: true},
+		"Deploy":    {Path: "Deploy", Title: "Deploy"},
+	}
+	store := &state.Store{
+		Favorites: []string{"db/Backup", "db/Removed"},
+		Recent:    []string{"db/Secret"},
+	}
+
+	currentPath := ""
+	m := NewCharmSelectorModel(charms, &currentPath).WithState(store)
+	m.updateOptions()
+
+	// The recent folder only holds a hidden charm, so it is not shown
+	if want := []string{FavoritesFolder, "Deploy", "db"}; !reflect.DeepEqual(m.options, want) {
+		t.Fatalf("root options = %v, want %v", m.options, want)
+	}
+
+	m.handleEnter()
+	if want := []string{"..", "db/Backup"}; !reflect.DeepEqual(m.options, want) {
+		t.Fatalf("favorites options = %v, want %v", m.options, want)
+	}
+
+	m.navigateDown()
+	m.toggleFavorite()
+	if store.IsFavorite("db/Backup") {
+		t.Error("toggleFavorite() did not remove the favorite")
+	}
+	if want := []string{".."}; !reflect.DeepEqual(m.options, want) {
+		t.Errorf("favorites options after toggle = %v, want %v", m.options, want)
+	}
+}
+
+func TestPinnedFolders_NoSearchHighlights(t *testing.T) {
+	charms := map[string]models.CharmFunc{
+		"db/Backup": {Path: "db/Backup", Title: "Backup"},
+	}
+	store := &state.Store{Favorites: []string{"db/Backup"}}
+
+	currentPath := ""
+	m := NewCharmSelectorModel(charms, &currentPath).WithState(store)
+	m.searchTerm = "bkp"
+	m.updateOptions()
+	if len(m.searchMatches["db/Backup"].titleMatches) == 0 {
+		t.Fatalf("search did not match db/Backup")
+	}
+
+	m.handleEscape()
+	m.handleEnter()
+	if want := []string{"..", "db/Backup"}; !reflect.DeepEqual(m.options, want) {
+		t.Fatalf("favorites options = %v, want %v", m.options, want)
+	}
+	if match := m.searchMatches["db/Backup"]; len(match.titleMatches) > 0 || len(match.pathMatches) > 0 {
+		t.Errorf("favorites show highlights of the previous search: %+v", match)
+	}
+}
+
+func TestHistoryFolder(t *testing.T) {
+	charms := map[string]models.CharmFunc{
+		"Deploy": {Path: "Deploy", Title: "Deploy"},
+	}
+	store := &state.Store{}
+	for _, charm := range []string{"Removed", "Deploy"} {
+		if _, err := store.AddHistory(state.HistoryEntry{Charm: charm, Args: map[string]string{"env": "prod"}, Status: "ok"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	currentPath := ""
+	m := NewCharmSelectorModel(charms, &currentPath).WithState(store)
+	m.updateOptions()
+	if want := []string{HistoryFolder, "Deploy"}; !reflect.DeepEqual(m.options, want) {
+		t.Fatalf("root options = %v, want %v", m.options, want)
+	}
+
+	m.handleEnter()
+	if want := []string{"..", "#2", "#1"}; !reflect.DeepEqual(m.options, want) {
+		t.Fatalf("history options = %v, want %v", m.options, want)
+	}
+
+	// The charm of the oldest entry no longer exists, so it cannot be run again
+	m.moveCursorTo(2)
+	m.handleEnter()
+	if _, ok := m.Replay(); ok {
+		t.Error("Replay() returned an entry of a removed charm")
+	}
+
+	m.moveCursorTo(1)
+	m.handleEnter()
+	entry, ok := m.Replay()
+	if !ok || entry.ID != 2 || currentPath != "Deploy" {
+		t.Errorf("Replay() = %+v, %v with path %q, want entry 2 of Deploy", entry, ok, currentPath)
+	}
+}
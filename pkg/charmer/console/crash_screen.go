@@ -0,0 +1,82 @@
+package console
+
+import (
+	"fmt"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"strings"
+)
+
+// CrashScreenOptions describes a panicked charm
+type CrashScreenOptions struct {
+	Charm      string
+	Value      string
+	Stack      string
+	ReportPath string // Path of the written crash report, if any
+}
+
+// CrashScreen displays a scrollable view of a charm panic with its stack trace
+func CrashScreen(options CrashScreenOptions) error {
+	p := tea.NewProgram(crashScreenModel{options: options}, tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
+
+type crashScreenModel struct {
+	options  CrashScreenOptions
+	viewport viewport.Model
+	ready    bool
+}
+
+func (m crashScreenModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m crashScreenModel) header() string {
+	var builder strings.Builder
+	builder.WriteString(errorTitleStyle.Render(fmt.Sprintf("Charm %s panicked", m.options.Charm)))
+	builder.WriteString("\n\n")
+	builder.WriteString(errorMessageStyle.Render(m.options.Value))
+	builder.WriteString("\n")
+	if m.options.ReportPath != "" {
+		builder.WriteString(hintStyle.Render("Crash report written to " + m.options.ReportPath))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+func (m crashScreenModel) footer() string {
+	return hintStyle.Render(fmt.Sprintf("↑/↓ to scroll • %3.f%% • enter to close", m.viewport.ScrollPercent()*100))
+}
+
+func (m crashScreenModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Header, footer and the blank lines around the stack
+		height := msg.Height - strings.Count(m.header(), "\n") - 4
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, height)
+			m.viewport.SetContent(m.options.Stack)
+			m.ready = true
+		} else {
+			m.viewport.Width = msg.Width
+			m.viewport.Height = height
+		}
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter", "esc", "q", "ctrl+c":
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m crashScreenModel) View() string {
+	if !m.ready {
+		return m.header()
+	}
+	return m.header() + "\n" + m.viewport.View() + "\n\n" + m.footer()
+}
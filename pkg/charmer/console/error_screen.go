@@ -0,0 +1,106 @@
+package console
+
+import (
+	"fmt"
+	"github.com/ImGajeed76/charmer/pkg/charmer/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"strings"
+)
+
+// Style definitions, set by applyErrorScreenTheme
+var (
+	errorTitleStyle   lipgloss.Style
+	errorBoxStyle     lipgloss.Style
+	errorMessageStyle lipgloss.Style
+)
+
+func applyErrorScreenTheme(t theme.Theme) {
+	errorTitleStyle = lipgloss.NewStyle().
+		Foreground(t.Error).
+		Bold(true)
+
+	errorBoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Error).
+		Padding(1, 2)
+
+	errorMessageStyle = lipgloss.NewStyle().
+		Foreground(t.Secondary)
+}
+
+// ErrorScreenOptions allows customization of the error screen
+type ErrorScreenOptions struct {
+	Title string
+}
+
+// DefaultErrorScreenOptions returns the default options
+func DefaultErrorScreenOptions() ErrorScreenOptions {
+	return ErrorScreenOptions{
+		Title: "Error",
+	}
+}
+
+// ErrorScreen displays an error until the user dismisses it
+func ErrorScreen(err error, opts ...ErrorScreenOptions) error {
+	if err == nil {
+		return nil
+	}
+
+	fmt.Print("\033[H\033[2J") // Clear screen
+	options := DefaultErrorScreenOptions()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	p := tea.NewProgram(errorScreenModel{options: options, err: err})
+	if _, runErr := p.Run(); runErr != nil {
+		return runErr
+	}
+	fmt.Print("\033[H\033[2J") // Clear screen
+
+	return nil
+}
+
+type errorScreenModel struct {
+	options ErrorScreenOptions
+	err     error
+	width   int
+}
+
+func (m errorScreenModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m errorScreenModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter", "esc", "q", "ctrl+c":
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m errorScreenModel) View() string {
+	var builder strings.Builder
+
+	box := errorBoxStyle
+	if m.width > 8 {
+		box = box.Width(m.width - 4)
+	}
+
+	content := errorTitleStyle.Render(m.options.Title) + "\n\n" +
+		errorMessageStyle.Render(m.err.Error())
+	builder.WriteString(box.Render(content))
+	builder.WriteString("\n\n")
+
+	// Add hint text
+	builder.WriteString(hintStyle.Render("(press enter to continue)"))
+	builder.WriteString("\n")
+
+	return builder.String()
+}
@@ -0,0 +1,86 @@
+package console
+
+import (
+	"fmt"
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"strconv"
+)
+
+// ParamForm prompts for every parameter of a charm using the matching console
+// widget and returns the parsed values in declaration order.
+// Enum values are returned as the index of the selected option.
+func ParamForm(params []models.CharmParam) ([]any, error) {
+	values := make([]any, 0, len(params))
+
+	for _, param := range params {
+		value, err := promptParam(param)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func promptParam(param models.CharmParam) (any, error) {
+	switch param.Type {
+	case models.ParamBool:
+		defaultYes := false
+		if param.Default != "" {
+			parsed, err := strconv.ParseBool(param.Default)
+			if err != nil {
+				return nil, fmt.Errorf("invalid default for %s: %v", param.Name, err)
+			}
+			defaultYes = parsed
+		}
+		return YesNo(YesNoOptions{
+			Name:       param.Name,
+			Prompt:     param.Label(),
+			DefaultYes: defaultYes,
+			YesText:    "Yes",
+			NoText:     "No",
+		})
+	case models.ParamEnum:
+		if len(param.Options) == 0 {
+			return nil, fmt.Errorf("parameter %s has no options", param.Name)
+		}
+		// Items are the option indices, so the options keep their declared order and the default is preselected
+		indices := make([]int, len(param.Options))
+		for i := range indices {
+			indices[i] = i
+		}
+		return Select(indices, SelectOptions[int]{
+			Name:    param.Name,
+			Title:   param.Label(),
+			Label:   func(i int) string { return param.Options[i] },
+			Default: func(i int) bool { return param.Options[i] == param.Default },
+		})
+	default:
+		raw, err := Input(paramInputOptions(param))
+		if err != nil {
+			return nil, err
+		}
+		return param.Parse(raw)
+	}
+}
+
+// paramInputOptions returns the input options for a parameter typed in as text.
+// The value is parsed on every keystroke, so parsing must never exit the program.
+func paramInputOptions(param models.CharmParam) InputOptions {
+	return InputOptions{
+		Name:       param.Name,
+		Prompt:     param.Label(),
+		Regex:      param.Regex,
+		RegexError: "Input format is invalid",
+		Default:    param.Default,
+		CharLimit:  156,
+		Width:      40,
+		Required:   param.Type != models.ParamString,
+		Secret:     param.Secret,
+		Validate: func(value string) error {
+			_, err := param.Parse(value)
+			return err
+		},
+	}
+}
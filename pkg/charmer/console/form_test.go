@@ -0,0 +1,68 @@
+package console
+
+import (
+	"testing"
+
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+)
+
+func TestParamInputOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   models.CharmParam
+		value   string
+		wantErr bool
+	}{
+		{name: "Absolute path", param: models.CharmParam{Name: "dst", Type: models.ParamPath}, value: "/tmp/backup"},
+		{name: "Partial path", param: models.CharmParam{Name: "dst", Type: models.ParamPath}, value: "x", wantErr: true},
+		{name: "Empty segment", param: models.CharmParam{Name: "dst", Type: models.ParamPath}, value: "/tmp//x", wantErr: true},
+		{name: "Whole number", param: models.CharmParam{Name: "count", Type: models.ParamInt}, value: "3"},
+		{name: "Not a number", param: models.CharmParam{Name: "count", Type: models.ParamInt}, value: "three", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := paramInputOptions(tt.param).Validate(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParamForm_InvalidPath(t *testing.T) {
+	SetHeadless(map[string]string{"dst": "foo"})
+	defer func() {
+		headless.enabled, headless.values = false, nil
+	}()
+
+	_, err := ParamForm([]models.CharmParam{{Name: "dst", Type: models.ParamPath}})
+	if err == nil {
+		t.Fatalf("ParamForm() should fail for a relative path")
+	}
+}
+
+func TestParamForm_EnumDefault(t *testing.T) {
+	SetHeadless(map[string]string{})
+	defer func() {
+		headless.enabled, headless.values = false, nil
+	}()
+
+	param := models.CharmParam{Name: "mode", Type: models.ParamEnum, Options: []string{"Fast", "Safe", "Slow"}, Default: "Slow"}
+	values, err := ParamForm([]models.CharmParam{param})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != 2 {
+		t.Errorf("ParamForm() = %v, want the index 2 of the default", values[0])
+	}
+
+	// Indices given instead of a label refer to the declared order
+	SetHeadless(map[string]string{"mode": "1"})
+	values, err = ParamForm([]models.CharmParam{param})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != 1 {
+		t.Errorf("ParamForm() = %v, want 1", values[0])
+	}
+}
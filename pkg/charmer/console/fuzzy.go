@@ -0,0 +1,168 @@
+package console
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Scoring of fuzzy matches, loosely modelled after fzf: every matched character scores,
+// characters at word boundaries and runs of consecutive characters score extra and gaps cost.
+const (
+	scoreMatch       = 16
+	scoreGapStart    = -3
+	scoreGapExtend   = -1
+	bonusBoundary    = 8
+	bonusCamelCase   = 6
+	bonusConsecutive = 5
+)
+
+// noMatch marks impossible states in the scoring table
+const noMatch = -1 << 30
+
+// fuzzyMatch reports whether all characters of pattern appear in text in order, ignoring case.
+// It returns the best score and the rune positions of the matched characters in text.
+func fuzzyMatch(pattern, text string) (int, []int, bool) {
+	p := toLower([]rune(pattern))
+	t := []rune(text)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+	if len(p) > len(t) {
+		return 0, nil, false
+	}
+
+	lower := toLower(t)
+	if !isSubsequence(p, lower) {
+		return 0, nil, false
+	}
+
+	// score[i][j] is the best score of matching p[:i+1] with p[i] at t[j], from[i][j] the position of p[i-1]
+	score := make([][]int, len(p))
+	from := make([][]int, len(p))
+	for i := range p {
+		score[i] = make([]int, len(t))
+		from[i] = make([]int, len(t))
+		for j := range t {
+			score[i][j] = noMatch
+		}
+	}
+
+	for j := range t {
+		if lower[j] == p[0] {
+			score[0][j] = scoreMatch + charBonus(t, j)
+		}
+	}
+
+	for i := 1; i < len(p); i++ {
+		// Best previous position with at least one skipped character, normalized by its index
+		bestGap, bestGapIdx := noMatch, -1
+
+		for j := i; j < len(t); j++ {
+			if k := j - 2; k >= 0 && score[i-1][k] != noMatch {
+				if value := score[i-1][k] - scoreGapExtend*k; value > bestGap {
+					bestGap, bestGapIdx = value, k
+				}
+			}
+			if lower[j] != p[i] {
+				continue
+			}
+
+			base := scoreMatch + charBonus(t, j)
+			if prev := score[i-1][j-1]; prev != noMatch {
+				score[i][j] = prev + base + bonusConsecutive
+				from[i][j] = j - 1
+			}
+			if bestGapIdx >= 0 {
+				gap := bestGap + scoreGapExtend*(j-1) + scoreGapStart + base
+				if gap > score[i][j] {
+					score[i][j] = gap
+					from[i][j] = bestGapIdx
+				}
+			}
+		}
+	}
+
+	last := len(p) - 1
+	best, bestIdx := noMatch, -1
+	for j := range t {
+		if score[last][j] > best {
+			best, bestIdx = score[last][j], j
+		}
+	}
+	if bestIdx < 0 {
+		return 0, nil, false
+	}
+
+	positions := make([]int, len(p))
+	for i, j := last, bestIdx; i >= 0; i-- {
+		positions[i] = j
+		j = from[i][j]
+	}
+
+	return best, positions, true
+}
+
+// toLower lowercases rune by rune, so positions in the result match the input
+func toLower(runes []rune) []rune {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
+
+// isSubsequence cheaply rules out texts before the scoring table is built
+func isSubsequence(pattern, text []rune) bool {
+	i := 0
+	for _, r := range text {
+		if i < len(pattern) && r == pattern[i] {
+			i++
+		}
+	}
+	return i == len(pattern)
+}
+
+// charBonus rewards characters that start a word, a path segment or a camelCase hump
+func charBonus(text []rune, i int) int {
+	if i == 0 {
+		return bonusBoundary
+	}
+	prev, cur := text[i-1], text[i]
+	switch {
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusCamelCase
+	}
+	return 0
+}
+
+// highlightMatches renders the runes of text at the given positions with the searchMatch style
+func highlightMatches(text string, positions []int) string {
+	if len(positions) == 0 {
+		return text
+	}
+
+	matched := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		matched[pos] = true
+	}
+
+	var result, run strings.Builder
+	for i, r := range []rune(text) {
+		if matched[i] {
+			run.WriteRune(r)
+			continue
+		}
+		if run.Len() > 0 {
+			result.WriteString(styles.searchMatch.Render(run.String()))
+			run.Reset()
+		}
+		result.WriteRune(r)
+	}
+	if run.Len() > 0 {
+		result.WriteString(styles.searchMatch.Render(run.String()))
+	}
+
+	return result.String()
+}
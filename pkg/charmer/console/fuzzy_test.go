@@ -0,0 +1,77 @@
+package console
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		text          string
+		wantOk        bool
+		wantPositions []int
+	}{
+		{
+			name:          "Empty pattern",
+			pattern:       "",
+			text:          "Deploy",
+			wantOk:        true,
+			wantPositions: nil,
+		},
+		{
+			name:          "Case insensitive subsequence",
+			pattern:       "dpl",
+			text:          "Deploy",
+			wantOk:        true,
+			wantPositions: []int{0, 2, 3},
+		},
+		{
+			name:          "Prefers word boundaries",
+			pattern:       "db",
+			text:          "deploy/db/backup",
+			wantOk:        true,
+			wantPositions: []int{7, 8},
+		},
+		{
+			name:          "Prefers camel case humps",
+			pattern:       "bd",
+			text:          "backupDatabase",
+			wantOk:        true,
+			wantPositions: []int{0, 6},
+		},
+		{
+			name:    "Wrong order",
+			pattern: "yd",
+			text:    "Deploy",
+			wantOk:  false,
+		},
+		{
+			name:    "Pattern longer than text",
+			pattern: "deployment",
+			text:    "deploy",
+			wantOk:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, positions, ok := fuzzyMatch(tt.pattern, tt.text)
+			if ok != tt.wantOk {
+				t.Fatalf("fuzzyMatch(%q, %q) ok = %v, want %v", tt.pattern, tt.text, ok, tt.wantOk)
+			}
+			if !reflect.DeepEqual(positions, tt.wantPositions) {
+				t.Errorf("fuzzyMatch(%q, %q) positions = %v, want %v", tt.pattern, tt.text, positions, tt.wantPositions)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatch_ConsecutiveScoresHigher(t *testing.T) {
+	consecutive, _, _ := fuzzyMatch("back", "backup")
+	scattered, _, _ := fuzzyMatch("back", "bootstrap-cache-kit")
+	if consecutive <= scattered {
+		t.Errorf("consecutive score %d should be higher than scattered score %d", consecutive, scattered)
+	}
+}
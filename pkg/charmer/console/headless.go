@@ -0,0 +1,55 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/term"
+	"os"
+	"sync"
+)
+
+// ErrNotInteractive is returned by prompts that need a terminal
+// when no value was provided for them in headless mode
+var ErrNotInteractive = errors.New("no interactive terminal available")
+
+var headless = struct {
+	sync.RWMutex
+	enabled bool
+	values  map[string]string
+}{}
+
+// SetHeadless switches all prompts to non-interactive mode.
+// Prompts with a Name read their value from values (usually parsed from --name=value flags)
+// instead of opening a terminal UI, and fail fast if no value is available.
+func SetHeadless(values map[string]string) {
+	headless.Lock()
+	defer headless.Unlock()
+	headless.enabled = true
+	headless.values = values
+}
+
+// IsInteractive reports whether prompts can open a terminal UI
+func IsInteractive() bool {
+	headless.RLock()
+	defer headless.RUnlock()
+	return !headless.enabled && term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// headlessValue returns the value provided for a named prompt in headless mode
+func headlessValue(name string) (string, bool) {
+	headless.RLock()
+	defer headless.RUnlock()
+	if name == "" {
+		return "", false
+	}
+	value, ok := headless.values[name]
+	return value, ok
+}
+
+// notInteractiveError builds the error returned when a prompt can't be answered
+func notInteractiveError(name, prompt string) error {
+	if name == "" {
+		return fmt.Errorf("%w: prompt %q needs input", ErrNotInteractive, prompt)
+	}
+	return fmt.Errorf("%w: provide -- --%s for prompt %q", ErrNotInteractive, name, prompt)
+}
@@ -2,7 +2,7 @@ package console
 
 import (
 	"fmt"
-	constants "github.com/ImGajeed76/charmer/internal"
+	"github.com/ImGajeed76/charmer/pkg/charmer/theme"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -10,29 +10,38 @@ import (
 	"strings"
 )
 
+// Style definitions, set by applyInputTheme
 var (
-	// Style definitions
+	promptStyle      lipgloss.Style
+	inputStyle       lipgloss.Style
+	errorStyle       lipgloss.Style
+	hintStyle        lipgloss.Style
+	placeholderStyle lipgloss.Style
+)
+
+func applyInputTheme(t theme.Theme) {
 	promptStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(constants.Theme.PrimaryColor)).
-			Bold(true)
+		Foreground(t.Primary).
+		Bold(true)
 
 	inputStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(constants.Theme.PrimaryColor))
+		Foreground(t.Primary)
 
 	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(constants.Theme.ErrorColor)).
-			Italic(true)
+		Foreground(t.Error).
+		Italic(true)
 
 	hintStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(constants.Theme.TertiaryColor)).
-			Italic(true)
+		Foreground(t.Muted).
+		Italic(true)
 
 	placeholderStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(constants.Theme.TertiaryColor))
-)
+		Foreground(t.Muted)
+}
 
 // InputOptions allows customization of the input behavior
 type InputOptions struct {
+	Name        string // Flag name used to read the value in headless mode (--name=value)
 	Prompt      string
 	Regex       string
 	RegexError  string // Custom error message for regex validation
@@ -40,7 +49,12 @@ type InputOptions struct {
 	Placeholder string
 	CharLimit   int
 	Width       int
-	Required    bool // If true, empty input is not allowed
+	Required    bool                  // If true, empty input is not allowed
+	Validate    func(string) error    // Optional custom validation, run after the regex check
+	Secret      bool                  // Masks the input, e.g. for passwords and tokens. ctrl+r reveals it
+	Confirm     bool                  // Asks for the value a second time, both entries have to match
+	Suggestions func(string) []string // Suggests values for the input, e.g. StaticSuggestions or PathSuggestions. tab completes
+	HistoryKey  string                // Remembers the entered values under this key, ↑/↓ recall them
 }
 
 // DefaultInputOptions returns the default options
@@ -56,13 +70,23 @@ func DefaultInputOptions() InputOptions {
 
 // Input takes a prompt and optional options, returns the validated user input
 func Input(opts ...InputOptions) (string, error) {
-	fmt.Print("\033[H\033[2J")
 	options := DefaultInputOptions()
 	if len(opts) > 0 {
 		options = opts[0]
 	}
 
-	p := tea.NewProgram(initialModel(options))
+	if !IsInteractive() {
+		return headlessInput(options)
+	}
+
+	fmt.Print("\033[H\033[2J")
+
+	model := initialModel(options)
+	if model.keepsHistory() {
+		model.history = loadPromptHistory(options.HistoryKey)
+	}
+
+	p := tea.NewProgram(model)
 	m, err := p.Run()
 	if err != nil {
 		return "", err
@@ -73,15 +97,51 @@ func Input(opts ...InputOptions) (string, error) {
 	if finalModel.quitted {
 		return "", fmt.Errorf("input cancelled")
 	}
-	return finalModel.textInput.Value(), nil
+
+	value := finalModel.textInput.Value()
+	if finalModel.keepsHistory() && value != "" {
+		addPromptHistory(options.HistoryKey, value)
+	}
+	return value, nil
+}
+
+// headlessInput resolves the input from the headless values or the default without a terminal
+func headlessInput(options InputOptions) (string, error) {
+	value, ok := headlessValue(options.Name)
+	if !ok {
+		if options.Default == "" {
+			return "", notInteractiveError(options.Name, options.Prompt)
+		}
+		value = options.Default
+	}
+
+	if valid, errMsg := initialModel(options).validateInput(value); !valid {
+		return "", fmt.Errorf("invalid value for %q: %s", options.Prompt, errMsg)
+	}
+	return value, nil
 }
 
 type inputModel struct {
-	textInput textinput.Model
-	options   InputOptions
-	regex     *regexp.Regexp
-	quitted   bool
-	err       error
+	textInput  textinput.Model
+	options    InputOptions
+	regex      *regexp.Regexp
+	quitted    bool
+	err        error
+	first      string // First entry while confirming
+	confirming bool   // The value is entered the second time
+	mismatch   bool   // The last two entries did not match
+
+	suggestions  []string // Suggestions for the current value, shown below the input
+	suggestion   int      // Index of the highlighted suggestion
+	history      []string // Previously entered values, most recent first
+	historyIndex int      // Index of the recalled value, -1 while editing the draft
+	draft        string   // Value typed before recalling the history
+}
+
+// suggestionsMsg carries the suggestions computed for a value of the input
+type suggestionsMsg struct {
+	prefix      string
+	suggestions []string
 }
 
 func initialModel(options InputOptions) inputModel {
@@ -101,6 +161,10 @@ func initialModel(options InputOptions) inputModel {
 	if options.Placeholder != "" {
 		ti.Placeholder = options.Placeholder
 	}
+	if options.Secret {
+		ti.EchoMode = textinput.EchoPassword
+		ti.EchoCharacter = '•'
+	}
 
 	var letterRegex *regexp.Regexp
 	if options.Regex != "" {
@@ -108,9 +172,10 @@ func initialModel(options InputOptions) inputModel {
 	}
 
 	return inputModel{
-		textInput: ti,
-		options:   options,
-		regex:     letterRegex,
+		textInput:    ti,
+		options:      options,
+		regex:        letterRegex,
+		historyIndex: -1,
 	}
 }
 
@@ -118,6 +183,56 @@ func (m inputModel) Init() tea.Cmd {
 	return textinput.Blink
 }
 
+// keepsHistory reports whether the entered values are remembered. Secrets never are.
+func (m inputModel) keepsHistory() bool {
+	return m.options.HistoryKey != "" && !m.options.Secret
+}
+
+// suggest computes the suggestions for the current value in the background,
+// so completers listing remote directories don't block typing
+func (m inputModel) suggest() tea.Cmd {
+	if m.options.Suggestions == nil || m.options.Secret {
+		return nil
+	}
+	prefix, suggestions := m.textInput.Value(), m.options.Suggestions
+	return func() tea.Msg {
+		return suggestionsMsg{prefix: prefix, suggestions: suggestions(prefix)}
+	}
+}
+
+// setSuggestions shows the suggestions for the current value, leaving out the value itself
+func (m *inputModel) setSuggestions(msg suggestionsMsg) {
+	// The value changed while the suggestions were computed
+	if msg.prefix != m.textInput.Value() {
+		return
+	}
+	m.suggestions, m.suggestion = nil, 0
+	for _, suggestion := range msg.suggestions {
+		if suggestion != msg.prefix {
+			m.suggestions = append(m.suggestions, suggestion)
+		}
+	}
+}
+
+// recall replaces the value with the entry of the history at the given index, -1 restores the draft
+func (m *inputModel) recall(index int) {
+	if index < -1 || index >= len(m.history) || index == m.historyIndex {
+		return
+	}
+	if m.historyIndex == -1 {
+		m.draft = m.textInput.Value()
+	}
+	m.historyIndex = index
+
+	value := m.draft
+	if index >= 0 {
+		value = m.history[index]
+	}
+	m.textInput.SetValue(value)
+	m.textInput.CursorEnd()
+	m.suggestions = nil
+}
+
 func (m inputModel) validateInput(input string) (bool, string) {
 	if m.options.Required && strings.TrimSpace(input) == "" {
 		return false, "Input is required"
@@ -125,6 +240,11 @@ func (m inputModel) validateInput(input string) (bool, string) {
 	if m.regex != nil && input != "" && !m.regex.MatchString(input) {
 		return false, m.options.RegexError
 	}
+	if m.options.Validate != nil && input != "" {
+		if err := m.options.Validate(input); err != nil {
+			return false, err.Error()
+		}
+	}
 	return true, ""
 }
 
@@ -132,21 +252,96 @@ func (m inputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case suggestionsMsg:
+		m.setSuggestions(msg)
+		return m, nil
 	case tea.KeyMsg:
 		switch msg.Type {
+		case tea.KeyTab:
+			if len(m.suggestions) == 0 {
+				return m, m.suggest()
+			}
+			m.textInput.SetValue(m.suggestions[m.suggestion])
+			m.textInput.CursorEnd()
+			m.suggestions, m.historyIndex = nil, -1
+			return m, m.suggest()
+		case tea.KeyCtrlN:
+			if m.suggestion < len(m.suggestions)-1 {
+				m.suggestion++
+			}
+			return m, nil
+		case tea.KeyCtrlP:
+			if m.suggestion > 0 {
+				m.suggestion--
+			}
+			return m, nil
+		case tea.KeyUp:
+			m.recall(m.historyIndex + 1)
+			return m, nil
+		case tea.KeyDown:
+			m.recall(m.historyIndex - 1)
+			return m, nil
 		case tea.KeyEnter:
-			if valid, _ := m.validateInput(m.textInput.Value()); valid {
+			value := m.textInput.Value()
+			if valid, _ := m.validateInput(value); !valid {
+				return m, nil
+			}
+			if !m.options.Confirm {
 				return m, tea.Quit
 			}
-			return m, nil
-		case tea.KeyCtrlC, tea.KeyEsc:
+			return m.confirm(value)
+		case tea.KeyEsc:
+			if len(m.suggestions) > 0 {
+				m.suggestions = nil
+				return m, nil
+			}
 			m.quitted = true
 			return m, tea.Quit
+		case tea.KeyCtrlC:
+			m.quitted = true
+			return m, tea.Quit
+		case tea.KeyCtrlR:
+			if m.options.Secret {
+				if m.textInput.EchoMode == textinput.EchoPassword {
+					m.textInput.EchoMode = textinput.EchoNormal
+				} else {
+					m.textInput.EchoMode = textinput.EchoPassword
+				}
+			}
+			return m, nil
 		}
 	}
 
+	value := m.textInput.Value()
 	m.textInput, cmd = m.textInput.Update(msg)
-	return m, cmd
+	if m.textInput.Value() == value {
+		return m, cmd
+	}
+
+	// The value was edited, so it is a new draft with new suggestions
+	m.historyIndex = -1
+	if m.textInput.Value() == "" {
+		m.suggestions = nil
+		return m, cmd
+	}
+	return m, tea.Batch(cmd, m.suggest())
+}
+
+// confirm handles a valid entry in confirm mode: the first entry is kept and asked for again,
+// the second one has to match it. On a mismatch both entries are asked for again.
+func (m inputModel) confirm(value string) (tea.Model, tea.Cmd) {
+	if !m.confirming {
+		m.first, m.confirming, m.mismatch = value, true, false
+		m.textInput.Reset()
+		m.suggestions = nil
+		return m, nil
+	}
+	if value == m.first {
+		return m, tea.Quit
+	}
+	m.first, m.confirming, m.mismatch = "", false, true
+	m.textInput.Reset()
+	return m, nil
 }
 
 func (m inputModel) View() string {
@@ -154,20 +349,61 @@ func (m inputModel) View() string {
 
 	// Add the prompt with styling
 	builder.WriteString(promptStyle.Render(m.options.Prompt))
+	if m.confirming {
+		builder.WriteString("\n")
+		builder.WriteString(hintStyle.Render("Enter it again to confirm"))
+	}
 	builder.WriteString("\n\n")
 
-	// Add the input field
+	// Add the input field and the suggestions, scrolled so the highlighted one stays visible
 	builder.WriteString(m.textInput.View())
-	builder.WriteString("\n\n")
+	builder.WriteString("\n")
+	if len(m.suggestions) > 0 {
+		start := max(0, m.suggestion-maxSuggestions+1)
+		end := min(len(m.suggestions), start+maxSuggestions)
+		for i := start; i < end; i++ {
+			if i == m.suggestion {
+				builder.WriteString(selectedItemStyle.Render("▸ " + m.suggestions[i]))
+			} else {
+				builder.WriteString(itemStyle.Render("  " + m.suggestions[i]))
+			}
+			builder.WriteString("\n")
+		}
+		if hidden := len(m.suggestions) - end; hidden > 0 {
+			builder.WriteString(hintStyle.Render(fmt.Sprintf("  … %d more", hidden)))
+			builder.WriteString("\n")
+		}
+	}
+	builder.WriteString("\n")
 
 	// Add error message if validation fails
 	if valid, errMsg := m.validateInput(m.textInput.Value()); !valid && m.textInput.Value() != "" {
 		builder.WriteString(errorStyle.Render(errMsg))
 		builder.WriteString("\n")
+	} else if m.mismatch {
+		builder.WriteString(errorStyle.Render("The entries did not match, please try again"))
+		builder.WriteString("\n")
 	}
 
 	// Add hint text
-	builder.WriteString(hintStyle.Render("(esc to cancel)"))
+	var hints []string
+	if m.options.Secret {
+		hints = append(hints, "ctrl+r to reveal")
+	}
+	if len(m.suggestions) > 0 {
+		hints = append(hints, "tab to complete", "ctrl+n/ctrl+p to choose")
+	} else if m.options.Suggestions != nil && !m.options.Secret {
+		hints = append(hints, "tab for suggestions")
+	}
+	if len(m.history) > 0 {
+		hints = append(hints, "↑/↓ for history")
+	}
+	if len(m.suggestions) > 0 {
+		hints = append(hints, "esc to close")
+	} else {
+		hints = append(hints, "esc to cancel")
+	}
+	builder.WriteString(hintStyle.Render("(" + strings.Join(hints, ", ") + ")"))
 	builder.WriteString("\n")
 
 	return builder.String()
@@ -188,5 +424,26 @@ func main() {
         Placeholder: "Enter name here",
         Required:    true,
     })
+
+    // Hostnames with completion, remembered between runs
+    host, _ := Input(InputOptions{
+        Prompt:      "Host:",
+        Suggestions: StaticSuggestions("web-1.example.com", "web-2.example.com", "db.example.com"),
+        HistoryKey:  "deploy.host",
+    })
+
+    // Local or sftp:// paths
+    file, _ := Input(InputOptions{
+        Prompt:      "File:",
+        Suggestions: PathSuggestions,
+        HistoryKey:  "deploy.file",
+    })
+
+    // Masked input, entered twice
+    token, _ := Input(InputOptions{
+        Prompt:  "API token:",
+        Secret:  true,
+        Confirm: true,
+    })
 }
 */
@@ -0,0 +1,102 @@
+package console
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestInputModel_Secret(t *testing.T) {
+	var model tea.Model = initialModel(InputOptions{Secret: true})
+	if got := model.(inputModel).textInput.EchoMode; got != textinput.EchoPassword {
+		t.Fatalf("secret input should be masked")
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if got := model.(inputModel).textInput.EchoMode; got != textinput.EchoNormal {
+		t.Errorf("ctrl+r should reveal the input")
+	}
+}
+
+func TestInputModel_Confirm(t *testing.T) {
+	enter := func(model tea.Model, value string) (tea.Model, tea.Cmd) {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(value)})
+		return model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	var model tea.Model = initialModel(InputOptions{Secret: true, Confirm: true})
+	model, cmd := enter(model, "hunter2")
+	if cmd != nil || !model.(inputModel).confirming {
+		t.Fatalf("the first entry should ask for confirmation")
+	}
+
+	model, cmd = enter(model, "hunter3")
+	if m := model.(inputModel); cmd != nil || m.confirming || !m.mismatch {
+		t.Fatalf("a mismatch should ask for both entries again")
+	}
+
+	model, _ = enter(model, "hunter2")
+	model, cmd = enter(model, "hunter2")
+	if cmd == nil || model.(inputModel).textInput.Value() != "hunter2" {
+		t.Errorf("matching entries should be accepted")
+	}
+}
+
+func TestInputModel_Suggestions(t *testing.T) {
+	var model tea.Model = initialModel(InputOptions{
+		Suggestions: StaticSuggestions("web-1", "web-2", "db-1"),
+	})
+
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	model, _ = model.Update(suggestionsMsg{prefix: "x", suggestions: []string{"x-1"}})
+	if got := model.(inputModel).suggestions; len(got) != 0 {
+		t.Fatalf("suggestions for an outdated value should be ignored, got %v", got)
+	}
+
+	// Typing computes the suggestions in the background, run them like the program would
+	model, _ = model.Update(model.(inputModel).suggest()())
+	if cmd == nil || len(model.(inputModel).suggestions) != 2 {
+		t.Fatalf("suggestions = %v, want web-1 and web-2", model.(inputModel).suggestions)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m := model.(inputModel)
+	if got := m.textInput.Value(); got != "web-2" {
+		t.Errorf("tab completed %q, want web-2", got)
+	}
+	if len(m.suggestions) != 0 {
+		t.Errorf("the dropdown should close after completing")
+	}
+}
+
+func TestInputModel_History(t *testing.T) {
+	model := initialModel(InputOptions{HistoryKey: "host"})
+	model.history = []string{"web-2", "web-1"}
+
+	keys := []struct {
+		key  tea.KeyMsg
+		want string
+	}{
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("db")}, "db"},
+		{tea.KeyMsg{Type: tea.KeyUp}, "web-2"},
+		{tea.KeyMsg{Type: tea.KeyUp}, "web-1"},
+		{tea.KeyMsg{Type: tea.KeyUp}, "web-1"},
+		{tea.KeyMsg{Type: tea.KeyDown}, "web-2"},
+		{tea.KeyMsg{Type: tea.KeyDown}, "db"},
+		{tea.KeyMsg{Type: tea.KeyDown}, "db"},
+	}
+
+	var m tea.Model = model
+	for i, k := range keys {
+		m, _ = m.Update(k.key)
+		if got := m.(inputModel).textInput.Value(); got != k.want {
+			t.Errorf("after key %d value = %q, want %q", i, got, k.want)
+		}
+	}
+
+	if initialModel(InputOptions{HistoryKey: "token", Secret: true}).keepsHistory() {
+		t.Errorf("secret inputs should not be remembered")
+	}
+}
@@ -0,0 +1,209 @@
+package console
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ImGajeed76/charmer/pkg/charmer/jobs"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// jobsTickInterval is how often the jobs panel and the running jobs counter are refreshed
+const jobsTickInterval = 250 * time.Millisecond
+
+// jobsTickMsg refreshes the jobs panel and the running jobs counter
+type jobsTickMsg struct{}
+
+// WithJobs enables background jobs. The Background key selects a charm to run as a job
+// of the manager, the Jobs key opens the panel listing them.
+func (m *CharmSelectorModel) WithJobs(manager *jobs.Manager) *CharmSelectorModel {
+	m.jobs = manager
+	m.jobProgress = progress.New(
+		progress.WithGradient(string(currentTheme.Gradient[0]), string(currentTheme.Gradient[1])),
+		progress.WithWidth(20),
+		progress.WithoutPercentage(),
+	)
+	return m
+}
+
+// Background reports whether the selected charm should run as a background job
+func (m *CharmSelectorModel) Background() bool {
+	return m.background
+}
+
+// keyMap returns the key bindings, without the job bindings if background jobs are disabled
+func (m *CharmSelectorModel) keyMap() SelectorKeyMap {
+	keys := m.keys
+	if m.jobs == nil {
+		keys.Background.SetEnabled(false)
+		keys.Jobs.SetEnabled(false)
+		keys.Cancel.SetEnabled(false)
+	}
+	return keys
+}
+
+// runningJobs returns the number of running background jobs
+func (m *CharmSelectorModel) runningJobs() int {
+	if m.jobs == nil {
+		return 0
+	}
+	return m.jobs.Running()
+}
+
+// scheduleJobsTick schedules the next refresh while jobs are running or the jobs panel is open
+func (m *CharmSelectorModel) scheduleJobsTick() tea.Cmd {
+	if m.ticking || (m.runningJobs() == 0 && !m.showJobs) {
+		return nil
+	}
+	m.ticking = true
+	return tea.Tick(jobsTickInterval, func(time.Time) tea.Msg {
+		return jobsTickMsg{}
+	})
+}
+
+// handleBackground selects the charm or history entry under the cursor to run as a background job
+func (m *CharmSelectorModel) handleBackground() (tea.Model, tea.Cmd) {
+	if len(m.options) == 0 {
+		return m, nil
+	}
+
+	option := m.options[m.cursor+m.offset]
+	if entry, ok := m.historyEntry(option); ok {
+		if _, exists := m.charms[entry.Charm]; !exists {
+			return m, nil
+		}
+	} else if _, ok := m.charmForOption(option); !ok {
+		return m, nil
+	}
+
+	m.background = true
+	m.handleEnter()
+	return m, tea.Quit
+}
+
+// handleJobsKey processes keyboard input while the jobs panel is open
+func (m *CharmSelectorModel) handleJobsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	jobList := m.jobs.Jobs()
+
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		*m.currentPath = "" // reset so no function gets called
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Jobs), key.Matches(msg, m.keys.Clear), msg.Type == tea.KeyEsc:
+		m.showJobs = false
+		m.lastSelectedOption = ""
+		m.prerenderDescription()
+	case key.Matches(msg, m.keys.Up):
+		if m.jobCursor > 0 {
+			m.jobCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.jobCursor < len(jobList)-1 {
+			m.jobCursor++
+		}
+	case key.Matches(msg, m.keys.Cancel):
+		if m.jobCursor < len(jobList) {
+			jobList[m.jobCursor].Cancel()
+		}
+	}
+	return m, nil
+}
+
+// jobsView renders the jobs panel: the list of jobs and the output of the selected one
+func (m *CharmSelectorModel) jobsView() string {
+	var view strings.Builder
+	view.WriteString(styles.helpTitle.Render("Background Jobs") + "\n")
+
+	jobList := m.jobs.Jobs()
+	if len(jobList) == 0 {
+		view.WriteString(fmt.Sprintf("No background jobs yet. Press %s on a charm to run it in the background.",
+			m.keys.Background.Help().Key))
+		return view.String()
+	}
+	m.jobCursor = min(m.jobCursor, len(jobList)-1)
+
+	// Show a window of the job list around the cursor, the rest of the card belongs to the output
+	maxJobs := max(3, m.descriptionMaxHeight/3)
+	start := max(0, m.jobCursor-maxJobs+1)
+	end := min(len(jobList), start+maxJobs)
+	for i := start; i < end; i++ {
+		view.WriteString(m.renderJob(i, jobList[i]) + "\n")
+	}
+
+	selected := jobList[m.jobCursor]
+	view.WriteString("\n" + styles.helpTitle.Render(fmt.Sprintf("Output of #%d", selected.ID)) + "\n")
+
+	lines := outputLines(selected)
+	available := max(1, m.descriptionMaxHeight-(end-start)-4)
+	if len(lines) > available {
+		lines = lines[len(lines)-available:]
+	}
+
+	clip := lipgloss.NewStyle().MaxWidth(max(m.descriptionMaxWidth, 20))
+	for _, line := range lines {
+		view.WriteString(clip.Render(line) + "\n")
+	}
+	return view.String()
+}
+
+// renderJob renders a job with its status, runtime and progress
+func (m *CharmSelectorModel) renderJob(index int, job *jobs.Job) string {
+	cursor := " "
+	if index == m.jobCursor {
+		cursor = ">"
+	}
+
+	status := job.Status()
+	line := fmt.Sprintf("%s %s #%d %s (%s) %s",
+		styles.cursor.Render(cursor),
+		jobStatusIcon(status),
+		job.ID,
+		job.Title,
+		job.Charm,
+		job.Duration().Round(time.Second))
+	if index == m.jobCursor {
+		line = styles.selectedItem.Render(line)
+	}
+
+	// The progress bar is added after the row style so its colors are not overridden
+	if progress, ok := job.Progress(); ok && status == jobs.Running {
+		line += " " + m.jobProgress.ViewAs(progress)
+	}
+	return line
+}
+
+// outputLines returns the captured output of a job with its error, if it failed
+func outputLines(job *jobs.Job) []string {
+	output := strings.TrimRight(job.Output(), "\n")
+
+	var lines []string
+	if output != "" {
+		lines = strings.Split(output, "\n")
+	}
+	switch status := job.Status(); {
+	case status == jobs.Failed && job.Err() != nil:
+		lines = append(lines, styles.jobError.Render("Job failed: "+job.Err().Error()))
+	case status != jobs.Running:
+		lines = append(lines, styles.jobsRunning.Render("Job "+status.String()))
+	case len(lines) == 0:
+		lines = append(lines, "No output yet")
+	}
+	return lines
+}
+
+// jobStatusIcon returns the icon shown for the status of a job
+func jobStatusIcon(status jobs.Status) string {
+	switch status {
+	case jobs.Running:
+		return "▶"
+	case jobs.Succeeded:
+		return "✓"
+	case jobs.Cancelled:
+		return "⊘"
+	}
+	return "✗"
+}
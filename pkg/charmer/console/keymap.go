@@ -0,0 +1,168 @@
+package console
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// SelectorKeyMap holds the key bindings of the charm selector
+type SelectorKeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	Select   key.Binding
+	Back     key.Binding
+	Clear    key.Binding
+	Search   key.Binding // When enabled, typing only searches after pressing it, otherwise typing always searches
+	Favorite key.Binding
+	Help     key.Binding
+	Quit     key.Binding
+
+	// Background jobs, only active when the selector has a job manager, see WithJobs
+	Background key.Binding // Runs the selected charm as a background job
+	Jobs       key.Binding // Opens and closes the jobs panel
+	Cancel     key.Binding // Cancels the job selected in the jobs panel
+}
+
+// DefaultSelectorKeyMap returns the default key bindings, typing a letter starts searching
+func DefaultSelectorKeyMap() SelectorKeyMap {
+	return SelectorKeyMap{
+		Up:       key.NewBinding(key.WithKeys("up"), key.WithHelp("↑", "up")),
+		Down:     key.NewBinding(key.WithKeys("down"), key.WithHelp("↓", "down")),
+		Select:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open")),
+		Back:     key.NewBinding(key.WithKeys("backspace"), key.WithHelp("backspace", "back")),
+		Clear:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear search")),
+		Search:   key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search"), key.WithDisabled()),
+		Favorite: key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "favorite")),
+		Help:     key.NewBinding(key.WithKeys("f1"), key.WithHelp("f1", "help")), // Printable keys are typed into the search
+		Quit:     key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+
+		Background: key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "run in background")),
+		Jobs:       key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "jobs")),
+		Cancel:     key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "cancel job")),
+	}
+}
+
+// VimSelectorKeyMap returns vim-style key bindings. Letters navigate, "/" starts searching.
+func VimSelectorKeyMap() SelectorKeyMap {
+	keys := DefaultSelectorKeyMap()
+	keys.Up = key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k/↑", "up"))
+	keys.Down = key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j/↓", "down"))
+	keys.Select = key.NewBinding(key.WithKeys("l", "enter"), key.WithHelp("l/enter", "open"))
+	keys.Back = key.NewBinding(key.WithKeys("h", "backspace"), key.WithHelp("h/backspace", "back"))
+	keys.Search.SetEnabled(true)
+	keys.Favorite = key.NewBinding(key.WithKeys("f", "ctrl+f"), key.WithHelp("f", "favorite"))
+	keys.Help = key.NewBinding(key.WithKeys("?", "f1"), key.WithHelp("?", "help"))
+	return keys
+}
+
+// EmacsSelectorKeyMap returns emacs-style key bindings, typing a letter starts searching
+func EmacsSelectorKeyMap() SelectorKeyMap {
+	keys := DefaultSelectorKeyMap()
+	keys.Up = key.NewBinding(key.WithKeys("ctrl+p", "up"), key.WithHelp("C-p/↑", "up"))
+	keys.Down = key.NewBinding(key.WithKeys("ctrl+n", "down"), key.WithHelp("C-n/↓", "down"))
+	keys.Select = key.NewBinding(key.WithKeys("ctrl+f", "enter"), key.WithHelp("C-f/enter", "open"))
+	keys.Back = key.NewBinding(key.WithKeys("ctrl+b", "backspace"), key.WithHelp("C-b/backspace", "back"))
+	keys.Clear = key.NewBinding(key.WithKeys("ctrl+g", "esc"), key.WithHelp("C-g/esc", "clear search"))
+	keys.Favorite = key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("C-t", "favorite"))
+	keys.Quit = key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("C-c", "quit"))
+	return keys
+}
+
+// SelectorKeyMapPreset returns the key bindings of a preset by name: default, vim or emacs
+func SelectorKeyMapPreset(name string) (SelectorKeyMap, bool) {
+	switch strings.ToLower(name) {
+	case "", "default":
+		return DefaultSelectorKeyMap(), true
+	case "vim":
+		return VimSelectorKeyMap(), true
+	case "emacs":
+		return EmacsSelectorKeyMap(), true
+	}
+	return SelectorKeyMap{}, false
+}
+
+// KeyMapConfig is the user configuration of the key bindings, usually read from a JSON file:
+//
+//	{"preset": "vim", "bindings": {"favorite": ["*"], "quit": ["ctrl+c"]}}
+//
+// Bindings are named like the SelectorKeyMap fields in lowercase. An empty list disables a binding.
+type KeyMapConfig struct {
+	Preset   string              `json:"preset"`
+	Bindings map[string][]string `json:"bindings"`
+}
+
+// WithConfig applies a user configuration on top of the key bindings.
+// A preset in the configuration replaces the key bindings before the single bindings are applied.
+func (k SelectorKeyMap) WithConfig(config KeyMapConfig) (SelectorKeyMap, error) {
+	if config.Preset != "" {
+		preset, ok := SelectorKeyMapPreset(config.Preset)
+		if !ok {
+			return k, fmt.Errorf("unknown keymap preset '%s'", config.Preset)
+		}
+		k = preset
+	}
+
+	bindings := k.bindings()
+
+	// Apply in a stable order, so errors are reported deterministically
+	names := make([]string, 0, len(config.Bindings))
+	for name := range config.Bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		binding, ok := bindings[strings.ToLower(name)]
+		if !ok {
+			return k, fmt.Errorf("unknown key binding '%s'", name)
+		}
+
+		keys := config.Bindings[name]
+		if len(keys) == 0 {
+			binding.SetEnabled(false)
+			continue
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(strings.Join(keys, "/"), binding.Help().Desc)
+		binding.SetEnabled(true)
+	}
+
+	return k, nil
+}
+
+// bindings returns the bindings by their configuration name
+func (k *SelectorKeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":       &k.Up,
+		"down":     &k.Down,
+		"select":   &k.Select,
+		"back":     &k.Back,
+		"clear":    &k.Clear,
+		"search":   &k.Search,
+		"favorite": &k.Favorite,
+		"help":     &k.Help,
+		"quit":     &k.Quit,
+
+		"background": &k.Background,
+		"jobs":       &k.Jobs,
+		"cancel":     &k.Cancel,
+	}
+}
+
+// ShortHelp returns the bindings shown in the help footer
+func (k SelectorKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Select, k.Back, k.Search, k.Jobs, k.Help, k.Quit}
+}
+
+// FullHelp returns the bindings shown in the help overlay
+func (k SelectorKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Select, k.Back},
+		{k.Search, k.Clear, k.Favorite},
+		{k.Background, k.Jobs, k.Cancel},
+		{k.Help, k.Quit},
+	}
+}
@@ -0,0 +1,93 @@
+package console
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSelectorKeyMap_WithConfig(t *testing.T) {
+	keys, err := DefaultSelectorKeyMap().WithConfig(KeyMapConfig{
+		Preset: "vim",
+		Bindings: map[string][]string{
+			"favorite": {"*"},
+			"quit":     {},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(keys.Up.Keys(), []string{"k", "up"}) {
+		t.Errorf("Up keys = %v, want the vim preset", keys.Up.Keys())
+	}
+	if !reflect.DeepEqual(keys.Favorite.Keys(), []string{"*"}) || keys.Favorite.Help().Key != "*" {
+		t.Errorf("Favorite = %v (help %q), want [*]", keys.Favorite.Keys(), keys.Favorite.Help().Key)
+	}
+	if keys.Quit.Enabled() {
+		t.Error("Quit should be disabled by an empty key list")
+	}
+
+	if _, err := DefaultSelectorKeyMap().WithConfig(KeyMapConfig{Preset: "nano"}); err == nil {
+		t.Error("expected an error for an unknown preset")
+	}
+	if _, err := DefaultSelectorKeyMap().WithConfig(KeyMapConfig{Bindings: map[string][]string{"jump": {"g"}}}); err == nil {
+		t.Error("expected an error for an unknown binding")
+	}
+}
+
+func TestCharmSelector_SearchFocus(t *testing.T) {
+	charms := map[string]models.CharmFunc{
+		"a/Run": {Path: "a/Run", Title: "Run"},
+		"b/Run": {Path: "b/Run", Title: "Run"},
+		"j/Run": {Path: "j/Run", Title: "Run"},
+	}
+	runes := func(r rune) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}} }
+
+	currentPath := ""
+	m := NewCharmSelectorModel(charms, &currentPath).WithKeyMap(VimSelectorKeyMap())
+	m.updateOptions()
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	// Without focus, letters are bindings
+	m.handleKeyPress(runes('j'))
+	if m.cursor != 1 || m.searchTerm != "" {
+		t.Fatalf("after j: cursor = %d, search = %q, want cursor 1 and no search", m.cursor, m.searchTerm)
+	}
+
+	// After "/", letters are typed into the search
+	m.handleKeyPress(runes('/'))
+	m.handleKeyPress(runes('j'))
+	if m.searchTerm != "j" {
+		t.Fatalf("search = %q, want %q", m.searchTerm, "j")
+	}
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.searchVisible() {
+		t.Error("esc should clear and unfocus the search")
+	}
+}
+
+func TestCharmSelector_HelpKey(t *testing.T) {
+	charms := map[string]models.CharmFunc{
+		"a/Run": {Path: "a/Run", Title: "Run?"},
+	}
+
+	currentPath := ""
+	m := NewCharmSelectorModel(charms, &currentPath)
+	m.updateOptions()
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	// Typing searches by default, so "?" is part of the search term
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	if m.showHelp || m.searchTerm != "?" {
+		t.Fatalf("after ?: help = %v, search = %q, want no help and search %q", m.showHelp, m.searchTerm, "?")
+	}
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyF1})
+	if !m.showHelp {
+		t.Error("f1 should show the help")
+	}
+}
@@ -0,0 +1,376 @@
+package console
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MultiSelectOptions allows customization of the multi select behavior
+type MultiSelectOptions struct {
+	Name     string // Flag name used to read the items (or their indices) in headless mode, comma separated
+	Title    string
+	Selected []int // Indices of the items selected initially
+	Min      int   // Minimum number of selected items, 0 for none
+	Max      int   // Maximum number of selected items, 0 for no limit
+	PageSize int   // Items per page, 0 to fit the terminal height
+}
+
+// DefaultMultiSelectOptions returns the default options
+func DefaultMultiSelectOptions() MultiSelectOptions {
+	return MultiSelectOptions{
+		Title: "Select options:",
+	}
+}
+
+// validateCount checks the number of selected items against Min and Max
+func (o MultiSelectOptions) validateCount(count int) error {
+	if count < o.Min {
+		return fmt.Errorf("select at least %d %s", o.Min, pluralItems(o.Min))
+	}
+	return o.validateMax(count)
+}
+
+// validateMax checks the number of selected items against Max only, used while selecting
+func (o MultiSelectOptions) validateMax(count int) error {
+	if o.Max > 0 && count > o.Max {
+		return fmt.Errorf("select at most %d %s", o.Max, pluralItems(o.Max))
+	}
+	return nil
+}
+
+func pluralItems(count int) string {
+	if count == 1 {
+		return "item"
+	}
+	return "items"
+}
+
+// MultiSelect takes a slice of strings and returns the indices of the selected items in ascending order.
+// Typing filters the list, space toggles the item under the cursor.
+func MultiSelect(items []string, opts ...MultiSelectOptions) ([]int, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no items provided")
+	}
+
+	options := DefaultMultiSelectOptions()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	if !IsInteractive() {
+		return headlessMultiSelect(items, options)
+	}
+
+	fmt.Print("\033[H\033[2J") // Clear screen
+
+	p := tea.NewProgram(initialMultiSelectModel(items, options))
+	m, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Print("\033[H\033[2J") // Clear screen
+
+	finalModel := m.(multiSelectModel)
+	if finalModel.quitted {
+		return nil, fmt.Errorf("selection cancelled")
+	}
+	return finalModel.selectedIndices(), nil
+}
+
+// headlessMultiSelect matches the comma separated headless value against the items,
+// falling back to numeric indices. Without a value the initial selection is used.
+func headlessMultiSelect(items []string, options MultiSelectOptions) ([]int, error) {
+	value, ok := headlessValue(options.Name)
+	if !ok && len(options.Selected) == 0 {
+		return nil, notInteractiveError(options.Name, options.Title)
+	}
+
+	selected := initialMultiSelectModel(items, options).selected
+	if ok {
+		clear(selected)
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			index := slices.Index(items, part)
+			if index < 0 {
+				number, err := strconv.Atoi(part)
+				if err != nil || number < 0 || number >= len(items) {
+					return nil, fmt.Errorf("invalid value for %q: %q is not one of the options", options.Title, part)
+				}
+				index = number
+			}
+			selected[index] = true
+		}
+	}
+
+	if err := options.validateCount(len(selected)); err != nil {
+		return nil, fmt.Errorf("invalid value for %q: %v", options.Title, err)
+	}
+	return sortedIndices(selected), nil
+}
+
+// multiSelectMatch is an item shown with the current filter
+type multiSelectMatch struct {
+	index     int
+	positions []int // Matched rune positions, highlighted in the view
+}
+
+type multiSelectModel struct {
+	items    []string
+	options  MultiSelectOptions
+	selected map[int]bool
+	filter   string
+	matches  []multiSelectMatch
+	cursor   int // Position in matches
+	height   int
+	err      error
+	quitted  bool
+}
+
+func initialMultiSelectModel(items []string, options MultiSelectOptions) multiSelectModel {
+	m := multiSelectModel{
+		items:    items,
+		options:  options,
+		selected: make(map[int]bool),
+	}
+	for _, index := range options.Selected {
+		if index >= 0 && index < len(items) {
+			m.selected[index] = true
+		}
+	}
+	m.applyFilter()
+	return m
+}
+
+// applyFilter fuzzy matches the items against the filter, keeping their order
+func (m *multiSelectModel) applyFilter() {
+	m.matches = nil
+	for i, item := range m.items {
+		if _, positions, ok := fuzzyMatch(m.filter, item); ok {
+			m.matches = append(m.matches, multiSelectMatch{index: i, positions: positions})
+		}
+	}
+	m.cursor = 0
+}
+
+// pageSize returns the number of items shown per page
+func (m multiSelectModel) pageSize() int {
+	if m.options.PageSize > 0 {
+		return m.options.PageSize
+	}
+	if m.height == 0 {
+		return 10
+	}
+	// Title, filter, page line, error and hint take 9 lines
+	return max(1, m.height-9)
+}
+
+// selectedIndices returns the selected indices in ascending order
+func (m multiSelectModel) selectedIndices() []int {
+	return sortedIndices(m.selected)
+}
+
+func sortedIndices(selected map[int]bool) []int {
+	indices := make([]int, 0, len(selected))
+	for index := range selected {
+		indices = append(indices, index)
+	}
+	slices.Sort(indices)
+	return indices
+}
+
+// toggle selects or deselects the item under the cursor
+func (m *multiSelectModel) toggle() {
+	if len(m.matches) == 0 {
+		return
+	}
+	index := m.matches[m.cursor].index
+	if m.selected[index] {
+		delete(m.selected, index)
+		return
+	}
+	if err := m.options.validateMax(len(m.selected) + 1); err != nil {
+		m.err = err
+		return
+	}
+	m.selected[index] = true
+}
+
+// selectAll selects all items matching the filter, if that does not exceed Max
+func (m *multiSelectModel) selectAll() {
+	count := len(m.selected)
+	for _, match := range m.matches {
+		if !m.selected[match.index] {
+			count++
+		}
+	}
+	if err := m.options.validateMax(count); err != nil {
+		m.err = err
+		return
+	}
+	for _, match := range m.matches {
+		m.selected[match.index] = true
+	}
+}
+
+// selectNone deselects all items matching the filter
+func (m *multiSelectModel) selectNone() {
+	for _, match := range m.matches {
+		delete(m.selected, match.index)
+	}
+}
+
+func (m multiSelectModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		m.err = nil
+		pageSize := m.pageSize()
+
+		switch msg.Type {
+		case tea.KeyCtrlC:
+			m.quitted = true
+			return m, tea.Quit
+		case tea.KeyEsc:
+			if m.filter == "" {
+				m.quitted = true
+				return m, tea.Quit
+			}
+			m.filter = ""
+			m.applyFilter()
+		case tea.KeyEnter:
+			if err := m.options.validateCount(len(m.selected)); err != nil {
+				m.err = err
+				return m, nil
+			}
+			return m, tea.Quit
+		case tea.KeyUp:
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case tea.KeyDown:
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+			}
+		case tea.KeyLeft, tea.KeyPgUp:
+			m.cursor = max(0, m.cursor-pageSize)
+		case tea.KeyRight, tea.KeyPgDown:
+			m.cursor = max(0, min(len(m.matches)-1, m.cursor+pageSize))
+		case tea.KeySpace:
+			m.toggle()
+		case tea.KeyCtrlA:
+			m.selectAll()
+		case tea.KeyCtrlD:
+			m.selectNone()
+		case tea.KeyBackspace:
+			if m.filter != "" {
+				runes := []rune(m.filter)
+				m.filter = string(runes[:len(runes)-1])
+				m.applyFilter()
+			}
+		case tea.KeyRunes:
+			m.filter += string(msg.Runes)
+			m.applyFilter()
+		}
+	}
+
+	return m, nil
+}
+
+func (m multiSelectModel) View() string {
+	var builder strings.Builder
+
+	// Title
+	builder.WriteString(titleStyle.Render(m.options.Title))
+	builder.WriteString("\n\n")
+
+	// Filter
+	if m.filter == "" {
+		builder.WriteString(hintStyle.Render("Type to filter"))
+	} else {
+		builder.WriteString(promptStyle.Render("Filter: ") + inputStyle.Render(m.filter))
+	}
+	builder.WriteString("\n\n")
+
+	// Items of the current page
+	pageSize := m.pageSize()
+	page := m.cursor / pageSize
+	start := page * pageSize
+	end := min(len(m.matches), start+pageSize)
+
+	if len(m.matches) == 0 {
+		builder.WriteString(itemStyle.Render("  No matching items") + "\n")
+	}
+	for i := start; i < end; i++ {
+		match := m.matches[i]
+		checkbox := "[ ]"
+		if m.selected[match.index] {
+			checkbox = "[x]"
+		}
+
+		item := checkbox + " " + highlightMatches(m.items[match.index], match.positions)
+		if i == m.cursor {
+			builder.WriteString(selectedItemStyle.Render("▸ " + item))
+		} else {
+			builder.WriteString(itemStyle.Render("  " + item))
+		}
+		builder.WriteString("\n")
+	}
+
+	// Page and selection count
+	builder.WriteString("\n")
+	status := fmt.Sprintf("%d selected", len(m.selected))
+	if m.options.Max > 0 {
+		status = fmt.Sprintf("%d/%d selected", len(m.selected), m.options.Max)
+	}
+	if pages := (len(m.matches) + pageSize - 1) / pageSize; pages > 1 {
+		status = fmt.Sprintf("Page %d/%d • %s", page+1, pages, status)
+	}
+	builder.WriteString(itemStyle.Render(status))
+	builder.WriteString("\n")
+
+	// Error message
+	if m.err != nil {
+		builder.WriteString(errorStyle.Render(m.err.Error()))
+	}
+	builder.WriteString("\n")
+
+	// Help text
+	builder.WriteString(hintStyle.Render("↑/↓ to move • ←/→ to change page • space to toggle • ctrl+a all • ctrl+d none • enter to confirm • esc to cancel"))
+
+	return builder.String()
+}
+
+// Example usage:
+/*
+func main() {
+    servers := []string{"web-1", "web-2", "db-1", "cache-1"}
+
+    // Simple usage
+    selected, err := MultiSelect(servers)
+    if err != nil {
+        fmt.Println("Error:", err)
+        return
+    }
+    for _, index := range selected {
+        fmt.Println("Selected:", servers[index])
+    }
+
+    // With constraints
+    selected, err = MultiSelect(servers, MultiSelectOptions{
+        Title: "Servers to restart:",
+        Min:   1,
+        Max:   2,
+    })
+}
+*/
@@ -0,0 +1,131 @@
+package console
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMultiSelectModel(t *testing.T) {
+	items := []string{"web-1", "web-2", "db-1", "cache-1"}
+	keys := map[string]tea.KeyMsg{
+		"space": {Type: tea.KeySpace, Runes: []rune{' '}},
+		"down":  {Type: tea.KeyDown},
+		"all":   {Type: tea.KeyCtrlA},
+		"none":  {Type: tea.KeyCtrlD},
+		"esc":   {Type: tea.KeyEsc},
+		"w":     {Type: tea.KeyRunes, Runes: []rune{'w'}},
+		"b":     {Type: tea.KeyRunes, Runes: []rune{'b'}},
+	}
+
+	tests := []struct {
+		name    string
+		options MultiSelectOptions
+		keys    []string
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "Toggle items",
+			keys: []string{"space", "down", "down", "space", "down", "space", "space"},
+			want: []int{0, 2},
+		},
+		{
+			name:    "Initial selection",
+			options: MultiSelectOptions{Selected: []int{3, 1}},
+			keys:    []string{"down", "space"},
+			want:    []int{3},
+		},
+		{
+			name: "Select all matching the filter",
+			keys: []string{"w", "b", "all", "esc"},
+			want: []int{0, 1},
+		},
+		{
+			name:    "Select none matching the filter",
+			options: MultiSelectOptions{Selected: []int{0, 1, 2}},
+			keys:    []string{"w", "b", "none", "esc"},
+			want:    []int{2},
+		},
+		{
+			name:    "Max stops selecting",
+			options: MultiSelectOptions{Max: 1},
+			keys:    []string{"space", "down", "space"},
+			want:    []int{0},
+			wantErr: true,
+		},
+		{
+			name:    "Max stops select all",
+			options: MultiSelectOptions{Max: 3},
+			keys:    []string{"all"},
+			want:    []int{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var model tea.Model = initialMultiSelectModel(items, tt.options)
+			for _, k := range tt.keys {
+				model, _ = model.Update(keys[k])
+			}
+
+			m := model.(multiSelectModel)
+			if got := m.selectedIndices(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectedIndices() = %v, want %v", got, tt.want)
+			}
+			if (m.err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", m.err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMultiSelectModel_Min(t *testing.T) {
+	var model tea.Model = initialMultiSelectModel([]string{"a", "b"}, MultiSelectOptions{Min: 1})
+
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || model.(multiSelectModel).err == nil {
+		t.Fatalf("confirming without a selection should fail with Min 1")
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if _, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Errorf("confirming with one selected item should quit")
+	}
+}
+
+func TestHeadlessMultiSelect(t *testing.T) {
+	items := []string{"web-1", "web-2", "db-1"}
+	SetHeadless(map[string]string{"servers": "db-1, 0", "unknown": "web-3", "empty": ""})
+	defer func() {
+		headless.enabled, headless.values = false, nil
+	}()
+
+	tests := []struct {
+		name    string
+		options MultiSelectOptions
+		want    []int
+		wantErr bool
+	}{
+		{name: "Items and indices", options: MultiSelectOptions{Name: "servers"}, want: []int{0, 2}},
+		{name: "Unknown item", options: MultiSelectOptions{Name: "unknown"}, wantErr: true},
+		{name: "Empty value", options: MultiSelectOptions{Name: "empty"}, want: []int{}},
+		{name: "Empty value below min", options: MultiSelectOptions{Name: "empty", Min: 1}, wantErr: true},
+		{name: "Initial selection without value", options: MultiSelectOptions{Name: "missing", Selected: []int{1}}, want: []int{1}},
+		{name: "No value", options: MultiSelectOptions{Name: "missing"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := headlessMultiSelect(items, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("headlessMultiSelect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("headlessMultiSelect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
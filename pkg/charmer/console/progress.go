@@ -2,6 +2,8 @@ package console
 
 import (
 	"fmt"
+	"github.com/ImGajeed76/charmer/pkg/charmer/jobs"
+	"github.com/ImGajeed76/charmer/pkg/charmer/theme"
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -23,7 +25,7 @@ type ProgressOptions struct {
 
 func DefaultProgressOptions() ProgressOptions {
 	return ProgressOptions{
-		GradientColors: []string{"#5956e0", "#e86ef6"},
+		GradientColors: []string{string(currentTheme.Gradient[0]), string(currentTheme.Gradient[1])},
 		Width:          maxWidth,
 		Padding:        padding,
 	}
@@ -108,9 +110,18 @@ func (m *progressModel) View() string {
 	return "\n" + pad + m.progress.View() + "\n\n"
 }
 
-var helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262")).Render
+var helpStyle func(strs ...string) string
+
+func applyProgressTheme(t theme.Theme) {
+	helpStyle = lipgloss.NewStyle().Foreground(t.Muted).Render
+}
 
 func NewProgressBar(opts ...ProgressOptions) *ProgressBar {
+	// Background jobs have no terminal, their progress is shown in the jobs panel
+	if jobs.IsJob() {
+		return newJobProgressBar()
+	}
+
 	options := DefaultProgressOptions()
 	if len(opts) > 0 {
 		options = opts[0]
@@ -172,3 +183,16 @@ func NewProgressBar(opts ...ProgressOptions) *ProgressBar {
 		},
 	}
 }
+
+// newJobProgressBar returns a progress bar that reports to the jobs panel of the charm selector
+func newJobProgressBar() *ProgressBar {
+	return &ProgressBar{
+		Update: func(total, count int64) {
+			jobs.ReportProgress(os.Stderr, count, total)
+		},
+		Close: func() {},
+		Finish: func() {
+			jobs.ReportProgress(os.Stderr, 1, 1)
+		},
+	}
+}
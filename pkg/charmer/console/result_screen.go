@@ -0,0 +1,101 @@
+package console
+
+import (
+	"fmt"
+	"github.com/ImGajeed76/charmer/pkg/charmer/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"strings"
+	"time"
+)
+
+// Style definitions, set by applyResultScreenTheme
+var (
+	resultSuccessStyle lipgloss.Style
+	resultBoxStyle     lipgloss.Style
+)
+
+func applyResultScreenTheme(t theme.Theme) {
+	resultSuccessStyle = lipgloss.NewStyle().
+		Foreground(t.Success).
+		Bold(true)
+
+	resultBoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Success).
+		Padding(0, 2)
+}
+
+// ResultScreenOptions describes the outcome of a charm run
+type ResultScreenOptions struct {
+	Title    string
+	Err      error
+	Duration time.Duration
+}
+
+// ResultScreen shows a summary of a finished charm below its output.
+// It returns true if the user wants to return to the menu, false if they want to quit.
+func ResultScreen(options ResultScreenOptions) (bool, error) {
+	p := tea.NewProgram(resultScreenModel{options: options})
+	m, err := p.Run()
+	if err != nil {
+		return false, err
+	}
+
+	return !m.(resultScreenModel).quitted, nil
+}
+
+type resultScreenModel struct {
+	options ResultScreenOptions
+	quitted bool
+	done    bool
+}
+
+func (m resultScreenModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m resultScreenModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter", "esc":
+			m.done = true
+			return m, tea.Quit
+		case "q", "ctrl+c":
+			m.done = true
+			m.quitted = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m resultScreenModel) View() string {
+	if m.done {
+		return ""
+	}
+
+	var builder strings.Builder
+
+	box := resultBoxStyle
+	var status string
+	if m.options.Err != nil {
+		box = box.BorderForeground(currentTheme.Error)
+		status = errorTitleStyle.Render(fmt.Sprintf("✗ %s failed", m.options.Title)) + "\n" +
+			errorMessageStyle.Render(m.options.Err.Error())
+	} else {
+		status = resultSuccessStyle.Render(fmt.Sprintf("✓ %s finished", m.options.Title))
+	}
+	status += "\n" + hintStyle.Render(fmt.Sprintf("took %s", m.options.Duration.Round(time.Millisecond)))
+
+	builder.WriteString("\n")
+	builder.WriteString(box.Render(status))
+	builder.WriteString("\n\n")
+
+	// Add hint text
+	builder.WriteString(hintStyle.Render("(press enter to return to the menu, q to quit)"))
+	builder.WriteString("\n")
+
+	return builder.String()
+}
@@ -2,27 +2,50 @@ package console
 
 import (
 	"fmt"
-	constants "github.com/ImGajeed76/charmer/internal"
+	"github.com/ImGajeed76/charmer/pkg/charmer/theme"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"strconv"
 	"strings"
 )
 
+// Style definitions, set by applySelectTheme
 var (
+	titleStyle           lipgloss.Style
+	itemStyle            lipgloss.Style
+	selectedItemStyle    lipgloss.Style
+	disabledItemStyle    lipgloss.Style
+	itemDescriptionStyle lipgloss.Style
+	groupHeaderStyle     lipgloss.Style
+)
+
+func applySelectTheme(t theme.Theme) {
 	titleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(constants.Theme.PrimaryColor)).
-			Bold(true)
+		Foreground(t.Primary).
+		Bold(true)
 
 	itemStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(constants.Theme.SecondaryColor))
+		Foreground(t.Secondary)
 
 	selectedItemStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(constants.Theme.PrimaryColor)).
-				Bold(true)
-)
+		Foreground(t.Primary).
+		Bold(true)
+
+	disabledItemStyle = lipgloss.NewStyle().
+		Foreground(t.Muted).
+		Strikethrough(true)
+
+	itemDescriptionStyle = lipgloss.NewStyle().
+		Foreground(t.Muted)
+
+	groupHeaderStyle = lipgloss.NewStyle().
+		Foreground(t.Accent).
+		Bold(true)
+}
 
 // ListSelectOptions allows customization of the list select behavior
 type ListSelectOptions struct {
+	Name  string // Flag name used to read the item (or its index) in headless mode
 	Title string
 }
 
@@ -39,12 +62,17 @@ func ListSelect(items []string, opts ...ListSelectOptions) (int, error) {
 		return -1, fmt.Errorf("no items provided")
 	}
 
-	fmt.Print("\033[H\033[2J") // Clear screen
 	options := DefaultListSelectOptions()
 	if len(opts) > 0 {
 		options = opts[0]
 	}
 
+	if !IsInteractive() {
+		return headlessListSelect(items, options)
+	}
+
+	fmt.Print("\033[H\033[2J") // Clear screen
+
 	p := tea.NewProgram(initialListModel(items, options))
 	m, err := p.Run()
 	if err != nil {
@@ -59,6 +87,23 @@ func ListSelect(items []string, opts ...ListSelectOptions) (int, error) {
 	return finalModel.cursor + finalModel.offset, nil
 }
 
+// headlessListSelect matches the headless value against the items, falling back to a numeric index
+func headlessListSelect(items []string, options ListSelectOptions) (int, error) {
+	value, ok := headlessValue(options.Name)
+	if !ok {
+		return -1, notInteractiveError(options.Name, options.Title)
+	}
+	for i, item := range items {
+		if item == value {
+			return i, nil
+		}
+	}
+	if index, err := strconv.Atoi(value); err == nil && index >= 0 && index < len(items) {
+		return index, nil
+	}
+	return -1, fmt.Errorf("invalid value for %q: %q is not one of the options", options.Title, value)
+}
+
 type listModel struct {
 	items    []string
 	cursor   int
@@ -0,0 +1,379 @@
+package console
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"strconv"
+	"strings"
+)
+
+// SelectOptions allows customization of the generic select behavior
+type SelectOptions[T any] struct {
+	Name        string // Flag name used to read the item's label (or its index) in headless mode
+	Title       string
+	Label       func(T) string // Text shown for an item, defaults to fmt.Sprint
+	Description func(T) string // Optional secondary line shown below an item
+	Disabled    func(T) string // Reason an item can't be selected, "" if it can
+	Group       func(T) string // Section an item is listed under, sections appear in order of their first item
+	Default     func(T) bool   // Preselects the first item it returns true for
+}
+
+// DefaultSelectOptions returns the default options
+func DefaultSelectOptions[T any]() SelectOptions[T] {
+	return SelectOptions[T]{
+		Title: "Select an option:",
+	}
+}
+
+// label returns the text shown for an item
+func (o SelectOptions[T]) label(item T) string {
+	if o.Label == nil {
+		return fmt.Sprint(item)
+	}
+	return o.Label(item)
+}
+
+// disabledReason returns why an item can't be selected, or "" if it can
+func (o SelectOptions[T]) disabledReason(item T) string {
+	if o.Disabled == nil {
+		return ""
+	}
+	return o.Disabled(item)
+}
+
+// Select shows the items with their labels and returns the chosen one.
+// Typing filters the items, disabled items are shown with their reason but can't be chosen.
+func Select[T any](items []T, opts ...SelectOptions[T]) (T, error) {
+	var zero T
+	if len(items) == 0 {
+		return zero, fmt.Errorf("no items provided")
+	}
+
+	options := DefaultSelectOptions[T]()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	if !IsInteractive() {
+		return headlessSelect(items, options)
+	}
+
+	model := initialSelectModel(items, options)
+	if model.cursor < 0 {
+		return zero, fmt.Errorf("all items are disabled")
+	}
+
+	fmt.Print("\033[H\033[2J") // Clear screen
+
+	p := tea.NewProgram(model)
+	m, err := p.Run()
+	if err != nil {
+		return zero, err
+	}
+	fmt.Print("\033[H\033[2J") // Clear screen
+
+	finalModel := m.(selectModel[T])
+	if finalModel.quitted {
+		return zero, fmt.Errorf("selection cancelled")
+	}
+	return items[finalModel.cursor], nil
+}
+
+// headlessSelect matches the headless value against the item labels, falling back to a numeric index.
+// Without a value the default item is used.
+func headlessSelect[T any](items []T, options SelectOptions[T]) (T, error) {
+	var zero T
+
+	index := -1
+	value, ok := headlessValue(options.Name)
+	if ok {
+		for i, item := range items {
+			if options.label(item) == value {
+				index = i
+				break
+			}
+		}
+		if number, err := strconv.Atoi(value); index < 0 && err == nil && number >= 0 && number < len(items) {
+			index = number
+		}
+		if index < 0 {
+			return zero, fmt.Errorf("invalid value for %q: %q is not one of the options", options.Title, value)
+		}
+	} else {
+		index = defaultIndex(items, options)
+		if index < 0 {
+			return zero, notInteractiveError(options.Name, options.Title)
+		}
+	}
+
+	if reason := options.disabledReason(items[index]); reason != "" {
+		return zero, fmt.Errorf("invalid value for %q: %q is disabled: %s", options.Title, options.label(items[index]), reason)
+	}
+	return items[index], nil
+}
+
+// defaultIndex returns the index of the default item, or -1 if there is none
+func defaultIndex[T any](items []T, options SelectOptions[T]) int {
+	if options.Default == nil {
+		return -1
+	}
+	for i, item := range items {
+		if options.Default(item) {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectRow is a line of the list: a group header or an item
+type selectRow struct {
+	header    string
+	index     int   // Index of the item, -1 for headers
+	positions []int // Matched rune positions of the label, highlighted in the view
+}
+
+type selectModel[T any] struct {
+	items   []T
+	options SelectOptions[T]
+	labels  []string
+	groups  []string // Group names in order of their first item
+	filter  string
+	rows    []selectRow
+	cursor  int // Index of the item under the cursor, -1 if no item can be selected
+	height  int
+	quitted bool
+}
+
+func initialSelectModel[T any](items []T, options SelectOptions[T]) selectModel[T] {
+	m := selectModel[T]{
+		items:   items,
+		options: options,
+		labels:  make([]string, len(items)),
+	}
+
+	seen := make(map[string]bool)
+	for i, item := range items {
+		m.labels[i] = options.label(item)
+		if options.Group != nil {
+			if group := options.Group(item); !seen[group] {
+				seen[group] = true
+				m.groups = append(m.groups, group)
+			}
+		}
+	}
+
+	m.applyFilter()
+	if index := defaultIndex(items, options); index >= 0 && options.disabledReason(items[index]) == "" {
+		m.cursor = index
+	}
+	return m
+}
+
+// applyFilter fuzzy matches the labels against the filter and lays out the rows,
+// keeping the order of the items within their groups
+func (m *selectModel[T]) applyFilter() {
+	m.rows = nil
+
+	matchRows := func(group string, grouped bool) []selectRow {
+		var rows []selectRow
+		for i, item := range m.items {
+			if grouped && m.options.Group(item) != group {
+				continue
+			}
+			if _, positions, ok := fuzzyMatch(m.filter, m.labels[i]); ok {
+				rows = append(rows, selectRow{index: i, positions: positions})
+			}
+		}
+		return rows
+	}
+
+	if len(m.groups) == 0 {
+		m.rows = matchRows("", false)
+	}
+	for _, group := range m.groups {
+		rows := matchRows(group, true)
+		if len(rows) == 0 {
+			continue
+		}
+		if group != "" {
+			m.rows = append(m.rows, selectRow{header: group, index: -1})
+		}
+		m.rows = append(m.rows, rows...)
+	}
+
+	// Put the cursor on the first item that can be selected
+	m.cursor = -1
+	for _, row := range m.rows {
+		if row.index >= 0 && m.options.disabledReason(m.items[row.index]) == "" {
+			m.cursor = row.index
+			break
+		}
+	}
+}
+
+// moveCursor moves the cursor to the next item in the given direction that can be selected
+func (m *selectModel[T]) moveCursor(direction int) {
+	current := m.cursorRow()
+	if current < 0 {
+		return
+	}
+	for i := current + direction; i >= 0 && i < len(m.rows); i += direction {
+		row := m.rows[i]
+		if row.index >= 0 && m.options.disabledReason(m.items[row.index]) == "" {
+			m.cursor = row.index
+			return
+		}
+	}
+}
+
+// cursorRow returns the row of the item under the cursor, or -1
+func (m selectModel[T]) cursorRow() int {
+	for i, row := range m.rows {
+		if row.index >= 0 && row.index == m.cursor {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m selectModel[T]) Init() tea.Cmd {
+	return nil
+}
+
+func (m selectModel[T]) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC:
+			m.quitted = true
+			return m, tea.Quit
+		case tea.KeyEsc:
+			if m.filter == "" {
+				m.quitted = true
+				return m, tea.Quit
+			}
+			m.filter = ""
+			m.applyFilter()
+		case tea.KeyEnter:
+			if m.cursor >= 0 {
+				return m, tea.Quit
+			}
+		case tea.KeyUp:
+			m.moveCursor(-1)
+		case tea.KeyDown:
+			m.moveCursor(1)
+		case tea.KeyBackspace:
+			if m.filter != "" {
+				runes := []rune(m.filter)
+				m.filter = string(runes[:len(runes)-1])
+				m.applyFilter()
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			m.filter += string(msg.Runes)
+			m.applyFilter()
+		}
+	}
+
+	return m, nil
+}
+
+// renderRow renders a group header or an item with its description and disabled reason
+func (m selectModel[T]) renderRow(row selectRow) string {
+	if row.index < 0 {
+		return groupHeaderStyle.Render(row.header)
+	}
+
+	item := m.items[row.index]
+	label := highlightMatches(m.labels[row.index], row.positions)
+
+	var line string
+	switch {
+	case row.index == m.cursor:
+		line = selectedItemStyle.Render("▸ " + label)
+	case m.options.disabledReason(item) != "":
+		line = "  " + disabledItemStyle.Render(m.labels[row.index]) + " " +
+			itemDescriptionStyle.Render("("+m.options.disabledReason(item)+")")
+	default:
+		line = itemStyle.Render("  " + label)
+	}
+
+	if m.options.Description != nil {
+		if description := m.options.Description(item); description != "" {
+			line += "\n" + itemDescriptionStyle.Render("    "+description)
+		}
+	}
+	return line
+}
+
+func (m selectModel[T]) View() string {
+	var builder strings.Builder
+
+	// Title
+	builder.WriteString(titleStyle.Render(m.options.Title))
+	builder.WriteString("\n\n")
+
+	// Filter
+	if m.filter == "" {
+		builder.WriteString(hintStyle.Render("Type to filter"))
+	} else {
+		builder.WriteString(promptStyle.Render("Filter: ") + inputStyle.Render(m.filter))
+	}
+	builder.WriteString("\n\n")
+
+	// Rows, scrolled so the cursor stays visible. Title, filter and hint take 7 lines.
+	lines := make([]string, 0, len(m.rows))
+	cursorRow, cursorLine := m.cursorRow(), 0
+	for i, row := range m.rows {
+		if i == cursorRow {
+			cursorLine = len(lines)
+		}
+		lines = append(lines, strings.Split(m.renderRow(row), "\n")...)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, itemStyle.Render("  No matching items"))
+	}
+
+	if maxLines := max(1, m.height-7); m.height > 0 && len(lines) > maxLines {
+		start := min(max(0, cursorLine-maxLines/2), len(lines)-maxLines)
+		lines = lines[start : start+maxLines]
+	}
+	builder.WriteString(strings.Join(lines, "\n"))
+	builder.WriteString("\n\n")
+
+	// Help text
+	builder.WriteString(hintStyle.Render("↑/↓ to move • type to filter • enter to select • esc to cancel"))
+
+	return builder.String()
+}
+
+// Example usage:
+/*
+type server struct {
+    Name   string
+    Region string
+    Down   bool
+}
+
+func main() {
+    servers := []server{{"web-1", "eu", false}, {"web-2", "us", true}, {"db-1", "eu", false}}
+
+    selected, err := Select(servers, SelectOptions[server]{
+        Title: "Server to connect to:",
+        Label: func(s server) string { return s.Name },
+        Group: func(s server) string { return s.Region },
+        Disabled: func(s server) string {
+            if s.Down {
+                return "offline"
+            }
+            return ""
+        },
+    })
+    if err != nil {
+        fmt.Println("Error:", err)
+        return
+    }
+    fmt.Println("Connecting to", selected.Name)
+}
+*/
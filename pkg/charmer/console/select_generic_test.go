@@ -0,0 +1,121 @@
+package console
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type testServer struct {
+	name   string
+	region string
+	down   bool
+}
+
+func testServerOptions() SelectOptions[testServer] {
+	return SelectOptions[testServer]{
+		Name:  "server",
+		Label: func(s testServer) string { return s.name },
+		Group: func(s testServer) string { return s.region },
+		Disabled: func(s testServer) string {
+			if s.down {
+				return "offline"
+			}
+			return ""
+		},
+	}
+}
+
+func TestSelectModel(t *testing.T) {
+	servers := []testServer{
+		{name: "web-1", region: "eu"},
+		{name: "web-2", region: "us", down: true},
+		{name: "db-1", region: "eu", down: true},
+		{name: "db-2", region: "us"},
+		{name: "cache-1", region: "eu"},
+	}
+
+	tests := []struct {
+		name string
+		def  string
+		keys []tea.KeyMsg
+		want string
+	}{
+		{
+			name: "Groups keep the order of their first item and skip disabled items",
+			keys: []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyDown}},
+			want: "db-2",
+		},
+		{
+			name: "Cursor stops at the last item",
+			keys: []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyDown}, {Type: tea.KeyDown}, {Type: tea.KeyDown}},
+			want: "db-2",
+		},
+		{
+			name: "Default item",
+			def:  "db-2",
+			keys: []tea.KeyMsg{{Type: tea.KeyUp}},
+			want: "cache-1",
+		},
+		{
+			name: "Filter",
+			keys: []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("db")}},
+			want: "db-2",
+		},
+		{
+			name: "Clearing the filter",
+			keys: []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("db")}, {Type: tea.KeyEsc}},
+			want: "web-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := testServerOptions()
+			options.Default = func(s testServer) bool { return s.name == tt.def }
+
+			var model tea.Model = initialSelectModel(servers, options)
+			for _, k := range tt.keys {
+				model, _ = model.Update(k)
+			}
+
+			m := model.(selectModel[testServer])
+			if got := servers[m.cursor].name; got != tt.want {
+				t.Errorf("selected %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeadlessSelect(t *testing.T) {
+	servers := []testServer{{name: "web-1"}, {name: "web-2", down: true}}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "Label", value: "web-1", want: "web-1"},
+		{name: "Index", value: "0", want: "web-1"},
+		{name: "Disabled item", value: "web-2", wantErr: true},
+		{name: "Unknown item", value: "web-3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetHeadless(map[string]string{"server": tt.value})
+			defer func() {
+				headless.enabled, headless.values = false, nil
+			}()
+
+			got, err := headlessSelect(servers, testServerOptions())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("headlessSelect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.name != tt.want {
+				t.Errorf("headlessSelect() = %q, want %q", got.name, tt.want)
+			}
+		})
+	}
+}
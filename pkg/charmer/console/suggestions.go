@@ -0,0 +1,116 @@
+package console
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ImGajeed76/charmer/pkg/charmer/path"
+	"github.com/ImGajeed76/charmer/pkg/charmer/state"
+)
+
+// maxSuggestions is the number of suggestions shown below an input
+const maxSuggestions = 8
+
+// StaticSuggestions returns a suggestion function for InputOptions.Suggestions
+// that suggests the items starting with the input, ignoring case
+func StaticSuggestions(items ...string) func(prefix string) []string {
+	return func(prefix string) []string {
+		var suggestions []string
+		for _, item := range items {
+			if strings.HasPrefix(strings.ToLower(item), strings.ToLower(prefix)) {
+				suggestions = append(suggestions, item)
+			}
+		}
+		return suggestions
+	}
+}
+
+// PathSuggestions completes local and sftp:// paths for InputOptions.Suggestions.
+// It lists the directory of the input and suggests the entries starting with its last segment,
+// directories with a trailing slash. Hidden entries are only suggested once the segment starts with a dot.
+func PathSuggestions(prefix string) []string {
+	i := strings.LastIndex(prefix, "/")
+	dir, partial := prefix[:i+1], prefix[i+1:]
+
+	directory, ok := suggestionDir(dir)
+	if !ok {
+		return nil
+	}
+	entries, err := directory.List()
+	if err != nil {
+		return nil
+	}
+
+	var suggestions []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, partial) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(partial, ".")) {
+			continue
+		}
+		// Listing a remote directory is one request, checking every entry one more, so stop early
+		if len(suggestions) == maxSuggestions*4 {
+			break
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+		suggestions = append(suggestions, dir+name)
+	}
+	slices.Sort(suggestions)
+	return suggestions
+}
+
+// suggestionDir parses the directory part of a path typed into an input. Relative local
+// paths are resolved against the working directory.
+func suggestionDir(dir string) (*path.Path, bool) {
+	if strings.HasPrefix(dir, "sftp://") {
+		// The host has to be complete before anything can be listed
+		if strings.Count(dir, "/") < 3 {
+			return nil, false
+		}
+		directory, err := path.Parse(dir)
+		return directory, err == nil
+	}
+	if strings.Contains(dir, "://") {
+		return nil, false
+	}
+
+	absolute, err := filepath.Abs(filepath.FromSlash(dir))
+	if err != nil {
+		return nil, false
+	}
+	directory, err := path.Parse(filepath.ToSlash(absolute))
+	return directory, err == nil
+}
+
+// promptHistory is where inputs with a HistoryKey keep the entered values
+var promptHistory = struct {
+	sync.Mutex
+	store *state.Store
+}{store: &state.Store{}}
+
+// SetHistoryStore sets the store inputs with a HistoryKey keep the entered values in.
+// Without one, the history is only kept in memory.
+func SetHistoryStore(store *state.Store) {
+	promptHistory.Lock()
+	defer promptHistory.Unlock()
+	promptHistory.store = store
+}
+
+// loadPromptHistory returns the values entered into the prompt with the given key, most recent first
+func loadPromptHistory(key string) []string {
+	promptHistory.Lock()
+	defer promptHistory.Unlock()
+	values, _ := promptHistory.store.PromptHistory(key)
+	return values
+}
+
+// addPromptHistory remembers a value entered into the prompt with the given key.
+// The history is a convenience, so failing to write it does not fail the prompt.
+func addPromptHistory(key, value string) {
+	promptHistory.Lock()
+	defer promptHistory.Unlock()
+	_ = promptHistory.store.AddPromptHistory(key, value)
+}
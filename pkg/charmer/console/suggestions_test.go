@@ -0,0 +1,65 @@
+package console
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStaticSuggestions(t *testing.T) {
+	suggest := StaticSuggestions("Web-1", "web-2", "db-1")
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{prefix: "", want: []string{"Web-1", "web-2", "db-1"}},
+		{prefix: "WEB", want: []string{"Web-1", "web-2"}},
+		{prefix: "db-1", want: []string{"db-1"}},
+		{prefix: "cache", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if got := suggest(tt.prefix); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StaticSuggestions()(%q) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathSuggestions(t *testing.T) {
+	dir := filepath.ToSlash(t.TempDir())
+	for _, name := range []string{"notes.txt", "nested/file.txt", ".hidden"} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{name: "Directory", prefix: dir + "/", want: []string{dir + "/nested/", dir + "/notes.txt"}},
+		{name: "Partial segment", prefix: dir + "/nes", want: []string{dir + "/nested/"}},
+		{name: "Hidden entries", prefix: dir + "/.", want: []string{dir + "/.hidden"}},
+		{name: "Nested directory", prefix: dir + "/nested/", want: []string{dir + "/nested/file.txt"}},
+		{name: "Missing directory", prefix: dir + "/missing/", want: nil},
+		{name: "Incomplete host", prefix: "sftp://exam", want: nil},
+		{name: "Unknown scheme", prefix: "http://example.com/", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PathSuggestions(tt.prefix); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PathSuggestions(%q) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/quick"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TextAreaOptions allows customization of the text area behavior
+type TextAreaOptions struct {
+	Name            string // Flag name used to read the value in headless mode (--name=value)
+	Prompt          string
+	Default         string
+	Placeholder     string
+	Language        string // Language of the text, e.g. "sql" or "yaml". Enables the highlighted preview
+	CharLimit       int    // Maximum number of characters, 0 for no limit
+	MaxLines        int    // Maximum number of lines, 0 for no limit
+	Width           int
+	Height          int                // Visible lines
+	HideLineNumbers bool               // If true, no line numbers are shown
+	Required        bool               // If true, empty input is not allowed
+	Validate        func(string) error // Optional custom validation
+}
+
+// DefaultTextAreaOptions returns the default options
+func DefaultTextAreaOptions() TextAreaOptions {
+	return TextAreaOptions{
+		Prompt: "Enter text:",
+		Width:  80,
+		Height: 10,
+	}
+}
+
+// validate checks the value against the limits and the validation options
+func (o TextAreaOptions) validate(value string) error {
+	if o.Required && strings.TrimSpace(value) == "" {
+		return errors.New("input is required")
+	}
+	if length := len([]rune(value)); o.CharLimit > 0 && length > o.CharLimit {
+		return fmt.Errorf("the text has %d characters, at most %d are allowed", length, o.CharLimit)
+	}
+	if lines := strings.Count(value, "\n") + 1; o.MaxLines > 0 && lines > o.MaxLines {
+		return fmt.Errorf("the text has %d lines, at most %d are allowed", lines, o.MaxLines)
+	}
+	if o.Validate != nil && value != "" {
+		return o.Validate(value)
+	}
+	return nil
+}
+
+// TextArea prompts for multi-line text, e.g. commit messages, SQL snippets or YAML fragments.
+// ctrl+s submits the text, ctrl+o opens it in $EDITOR and reads the result back.
+func TextArea(opts ...TextAreaOptions) (string, error) {
+	options := DefaultTextAreaOptions()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	if !IsInteractive() {
+		return headlessTextArea(options)
+	}
+
+	fmt.Print("\033[H\033[2J") // Clear screen
+
+	p := tea.NewProgram(initialTextAreaModel(options))
+	m, err := p.Run()
+	if err != nil {
+		return "", err
+	}
+	fmt.Print("\033[H\033[2J") // Clear screen
+
+	finalModel := m.(textAreaModel)
+	if finalModel.quitted {
+		return "", fmt.Errorf("input cancelled")
+	}
+	return finalModel.textarea.Value(), nil
+}
+
+// headlessTextArea resolves the text from the headless values or the default without a terminal
+func headlessTextArea(options TextAreaOptions) (string, error) {
+	value, ok := headlessValue(options.Name)
+	if !ok {
+		if options.Default == "" {
+			return "", notInteractiveError(options.Name, options.Prompt)
+		}
+		value = options.Default
+	}
+
+	if err := options.validate(value); err != nil {
+		return "", fmt.Errorf("invalid value for %q: %v", options.Prompt, err)
+	}
+	return value, nil
+}
+
+// editorFinishedMsg is sent when the external editor was closed
+type editorFinishedMsg struct {
+	path string
+	err  error
+}
+
+type textAreaModel struct {
+	textarea textarea.Model
+	options  TextAreaOptions
+	preview  bool // Shows the highlighted text instead of the editor
+	err      error
+	quitted  bool
+}
+
+func initialTextAreaModel(options TextAreaOptions) textAreaModel {
+	ta := textarea.New()
+	ta.Placeholder = options.Placeholder
+	ta.ShowLineNumbers = !options.HideLineNumbers
+	ta.CharLimit = options.CharLimit
+	ta.MaxHeight = options.MaxLines
+
+	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	ta.FocusedStyle.CursorLineNumber = lipgloss.NewStyle().Foreground(currentTheme.Primary)
+	ta.FocusedStyle.LineNumber = lipgloss.NewStyle().Foreground(currentTheme.Muted)
+	ta.FocusedStyle.Placeholder = placeholderStyle
+	ta.FocusedStyle.Prompt = lipgloss.NewStyle().Foreground(currentTheme.Border)
+
+	width, height := options.Width, options.Height
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 10
+	}
+	ta.SetWidth(width)
+	ta.SetHeight(height)
+
+	ta.SetValue(options.Default)
+	ta.Focus()
+
+	return textAreaModel{
+		textarea: ta,
+		options:  options,
+	}
+}
+
+func (m textAreaModel) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+func (m textAreaModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case editorFinishedMsg:
+		return m.readEditorFile(msg), nil
+	case tea.KeyMsg:
+		m.err = nil
+
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.quitted = true
+			return m, tea.Quit
+		case tea.KeyCtrlS:
+			if err := m.options.validate(m.textarea.Value()); err != nil {
+				m.err = err
+				return m, nil
+			}
+			return m, tea.Quit
+		case tea.KeyCtrlO:
+			return m.openEditor()
+		case tea.KeyCtrlR:
+			if m.options.Language != "" {
+				m.preview = !m.preview
+			}
+			return m, nil
+		}
+
+		// The preview is read-only
+		if m.preview {
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.textarea, cmd = m.textarea.Update(msg)
+	return m, cmd
+}
+
+// openEditor writes the text to a temporary file and opens it in the external editor
+func (m textAreaModel) openEditor() (tea.Model, tea.Cmd) {
+	file, err := os.CreateTemp("", "charmer-*"+languageExtension(m.options.Language))
+	if err != nil {
+		m.err = fmt.Errorf("error creating a temporary file: %v", err)
+		return m, nil
+	}
+	_, err = file.WriteString(m.textarea.Value())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		m.err = fmt.Errorf("error writing the temporary file: %v", err)
+		return m, nil
+	}
+
+	editor := editorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorFinishedMsg{path: file.Name(), err: err}
+	})
+}
+
+// readEditorFile reads the text back from the file edited in the external editor and removes it
+func (m textAreaModel) readEditorFile(msg editorFinishedMsg) textAreaModel {
+	defer os.Remove(msg.path)
+
+	if msg.err != nil {
+		m.err = fmt.Errorf("the editor failed: %v", msg.err)
+		return m
+	}
+	data, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.err = fmt.Errorf("error reading the edited text: %v", err)
+		return m
+	}
+
+	// Editors usually end the file with a newline the text did not have
+	value := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	m.textarea.SetValue(value)
+	if m.textarea.Value() != value {
+		m.err = errors.New("the edited text exceeds the limits and was cut off")
+	}
+	return m
+}
+
+// editorCommand returns the command of the external editor from $VISUAL or $EDITOR, e.g. "code --wait"
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// languageExtension returns the file extension of a language, so the external editor highlights it too
+func languageExtension(language string) string {
+	if language == "" {
+		return ".txt"
+	}
+	if lexer := lexers.Get(language); lexer != nil {
+		for _, pattern := range lexer.Config().Filenames {
+			if ext := filepath.Ext(pattern); ext != "" && !strings.ContainsAny(ext, "*?[") {
+				return ext
+			}
+		}
+	}
+	return ".txt"
+}
+
+// highlight renders the text with chroma in the color style matching the theme
+func highlight(text, language string) string {
+	style := "monokai"
+	if currentTheme.Markdown == "light" {
+		style = "github"
+	}
+
+	var builder strings.Builder
+	if err := quick.Highlight(&builder, text, language, "terminal256", style); err != nil {
+		return text
+	}
+	return builder.String()
+}
+
+// previewView renders the highlighted text with line numbers
+func (m textAreaModel) previewView() string {
+	lines := strings.Split(highlight(m.textarea.Value(), m.options.Language), "\n")
+	if m.textarea.Value() == "" {
+		lines = []string{placeholderStyle.Render(m.options.Placeholder)}
+	}
+
+	// Show the lines around the cursor of the editor
+	height := m.textarea.Height()
+	start := 0
+	if len(lines) > height {
+		start = min(max(0, m.textarea.Line()-height/2), len(lines)-height)
+	}
+	end := min(len(lines), start+height)
+
+	numberWidth := len(strconv.Itoa(len(lines)))
+	var builder strings.Builder
+	for i := start; i < end; i++ {
+		builder.WriteString(m.textarea.FocusedStyle.Prompt.Render(m.textarea.Prompt))
+		if !m.options.HideLineNumbers {
+			builder.WriteString(m.textarea.FocusedStyle.LineNumber.Render(fmt.Sprintf(" %*d ", numberWidth, i+1)))
+		}
+		builder.WriteString(lines[i])
+		builder.WriteString("\n")
+	}
+	for i := end - start; i < height; i++ {
+		builder.WriteString(m.textarea.FocusedStyle.Prompt.Render(m.textarea.Prompt) + "\n")
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+func (m textAreaModel) View() string {
+	var builder strings.Builder
+
+	// Add the prompt with styling
+	builder.WriteString(promptStyle.Render(m.options.Prompt))
+	builder.WriteString("\n\n")
+
+	// Add the text area, or the highlighted preview
+	if m.preview {
+		builder.WriteString(m.previewView())
+	} else {
+		builder.WriteString(m.textarea.View())
+	}
+	builder.WriteString("\n\n")
+
+	// Add the limits and the error message
+	var limits []string
+	if m.options.CharLimit > 0 {
+		limits = append(limits, fmt.Sprintf("%d/%d characters", m.textarea.Length(), m.options.CharLimit))
+	}
+	if m.options.MaxLines > 0 {
+		limits = append(limits, fmt.Sprintf("%d/%d lines", m.textarea.LineCount(), m.options.MaxLines))
+	}
+	if len(limits) > 0 {
+		builder.WriteString(hintStyle.Render(strings.Join(limits, " • ")))
+		builder.WriteString("\n")
+	}
+	if m.err != nil {
+		builder.WriteString(errorStyle.Render(m.err.Error()))
+		builder.WriteString("\n")
+	}
+
+	// Add hint text
+	hint := "ctrl+s to submit • ctrl+o to open in $EDITOR • esc to cancel"
+	switch {
+	case m.preview:
+		hint = "ctrl+r to edit • ctrl+s to submit • esc to cancel"
+	case m.options.Language != "":
+		hint = "ctrl+s to submit • ctrl+o to open in $EDITOR • ctrl+r to preview • esc to cancel"
+	}
+	builder.WriteString(hintStyle.Render("(" + hint + ")"))
+	builder.WriteString("\n")
+
+	return builder.String()
+}
+
+// Example usage:
+/*
+func main() {
+    // Simple usage
+    message, _ := TextArea()
+
+    // Highlighted SQL with limits
+    query, _ := TextArea(TextAreaOptions{
+        Prompt:    "Enter the query:",
+        Language:  "sql",
+        MaxLines:  50,
+        CharLimit: 4000,
+        Height:    12,
+        Required:  true,
+    })
+}
+*/
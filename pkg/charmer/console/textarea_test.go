@@ -0,0 +1,63 @@
+package console
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTextAreaOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TextAreaOptions
+		value   string
+		wantErr bool
+	}{
+		{name: "No limits", options: TextAreaOptions{}, value: "a\nb\nc"},
+		{name: "Required", options: TextAreaOptions{Required: true}, value: " \n ", wantErr: true},
+		{name: "Within the char limit", options: TextAreaOptions{CharLimit: 5}, value: "äöü\nß"},
+		{name: "Char limit", options: TextAreaOptions{CharLimit: 4}, value: "äöü\nß", wantErr: true},
+		{name: "Line limit", options: TextAreaOptions{MaxLines: 2}, value: "a\nb\nc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.options.validate(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTextAreaModel_ReadEditorFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TextAreaOptions
+		content string
+		want    string
+		wantErr bool
+	}{
+		{name: "Trailing newline is removed", content: "SELECT 1;\r\nSELECT 2;\n", want: "SELECT 1;\nSELECT 2;"},
+		{name: "Text beyond the limits is cut off", options: TextAreaOptions{MaxLines: 2}, content: "a\nb\nc\n", want: "a\nb", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "edit.sql")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			m := initialTextAreaModel(tt.options).readEditorFile(editorFinishedMsg{path: path})
+			if got := m.textarea.Value(); got != tt.want {
+				t.Errorf("value = %q, want %q", got, tt.want)
+			}
+			if (m.err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", m.err, tt.wantErr)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("the temporary file should be removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package console
+
+import "github.com/ImGajeed76/charmer/pkg/charmer/theme"
+
+// currentTheme is the theme all console widgets are styled with
+var currentTheme theme.Theme
+
+func init() {
+	SetTheme(theme.Dark)
+}
+
+// SetTheme styles the charm selector and all console widgets with the given theme.
+// It should be called before any widget is shown.
+func SetTheme(t theme.Theme) {
+	currentTheme = t
+
+	applySelectorTheme(t)
+	applyInputTheme(t)
+	applySelectTheme(t)
+	applyYesNoTheme(t)
+	applyBannerTheme(t)
+	applyErrorScreenTheme(t)
+	applyResultScreenTheme(t)
+	applyProgressTheme(t)
+}
+
+// CurrentTheme returns the theme set by SetTheme
+func CurrentTheme() theme.Theme {
+	return currentTheme
+}
@@ -0,0 +1,108 @@
+package charmer
+
+import (
+	"fmt"
+	constants "github.com/ImGajeed76/charmer/internal"
+	"github.com/ImGajeed76/charmer/pkg/charmer/console"
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"golang.org/x/term"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// PanicError is returned when a charm panicked
+type PanicError struct {
+	Charm string
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("charm %s panicked: %v", e.Charm, e.Value)
+}
+
+// callRecovered calls the charm and turns a panic into a *PanicError.
+// The terminal state is restored, since the charm may have panicked while a prompt was active.
+func callRecovered(charm models.CharmFunc, call func() error) (err error) {
+	fd := int(os.Stdin.Fd())
+	state, stateErr := term.GetState(fd)
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		if stateErr == nil {
+			_ = term.Restore(fd, state)
+		}
+		// Leave the alt screen and show the cursor again. Headless output is read by scripts
+		// or captured by the jobs panel, so it stays free of escape sequences.
+		if console.IsInteractive() {
+			fmt.Print("\033[?1049l\033[?25h")
+		}
+
+		err = &PanicError{
+			Charm: charm.Path,
+			Value: r,
+			Stack: debug.Stack(),
+		}
+	}()
+
+	return call()
+}
+
+// writeCrashReport writes a crash report to dir and returns its path.
+// It returns an empty path if dir is empty, which disables crash reports.
+func writeCrashReport(panicErr *PanicError, dir string) (string, error) {
+	if dir == "" {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating crash report directory: %v", err)
+	}
+
+	// The random suffix keeps reports of panics within the same second apart
+	now := time.Now()
+	file, err := os.CreateTemp(dir, fmt.Sprintf("crash-%s-*.log", now.Format("20060102-150405")))
+	if err != nil {
+		return "", fmt.Errorf("error creating crash report: %v", err)
+	}
+	defer file.Close()
+
+	report := fmt.Sprintf("Charmer crash report\n\n"+
+		"Time:    %s\n"+
+		"Charm:   %s\n"+
+		"Version: %s\n"+
+		"Go:      %s %s/%s\n\n"+
+		"Panic: %v\n\n%s",
+		now.Format(time.RFC3339), panicErr.Charm, constants.Version,
+		runtime.Version(), runtime.GOOS, runtime.GOARCH,
+		panicErr.Value, panicErr.Stack)
+
+	if _, err := file.WriteString(report); err != nil {
+		return "", fmt.Errorf("error writing crash report: %v", err)
+	}
+
+	return file.Name(), nil
+}
+
+// reportPanic writes the crash report to dir and shows the crash screen
+func reportPanic(panicErr *PanicError, dir string) {
+	reportPath, err := writeCrashReport(panicErr, dir)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+	}
+
+	if err := console.CrashScreen(console.CrashScreenOptions{
+		Charm:      panicErr.Charm,
+		Value:      fmt.Sprint(panicErr.Value),
+		Stack:      string(panicErr.Stack),
+		ReportPath: reportPath,
+	}); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n\n%s", panicErr, panicErr.Stack)
+	}
+}
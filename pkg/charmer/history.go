@@ -0,0 +1,148 @@
+package charmer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ImGajeed76/charmer/pkg/charmer/console"
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"github.com/ImGajeed76/charmer/pkg/charmer/path"
+	"github.com/ImGajeed76/charmer/pkg/charmer/state"
+)
+
+// replayFlag runs a charm again with the arguments recorded in the run history
+const replayFlag = "replay"
+
+// recordHistory adds every run to the run history of the store. It is the outermost
+// middleware, so runs vetoed by other middleware are recorded as well.
+func recordHistory(store *state.Store) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, charm models.CharmFunc, args []any) error {
+			entry := state.HistoryEntry{Charm: charm.Path, Start: time.Now()}
+			entry.Args, entry.Redacted = recordedArgs(charm.Params, args)
+
+			err := next(ctx, charm, args)
+			entry.End = time.Now()
+			entry.Status = runStatus(err)
+			if err != nil {
+				entry.Error = err.Error()
+			}
+
+			// A broken history file must not fail the charm
+			_, _ = store.AddHistory(entry)
+			return err
+		}
+	}
+}
+
+// recordedArgs converts the parsed arguments back into raw values by parameter name.
+// Secret parameters and sftp paths with a password are returned as redacted instead.
+func recordedArgs(params []models.CharmParam, args []any) (map[string]string, []string) {
+	var recorded map[string]string
+	var redacted []string
+
+	for i, param := range params {
+		if i >= len(args) {
+			break
+		}
+
+		raw, ok := formatArg(param, args[i])
+		if param.Secret || !ok {
+			redacted = append(redacted, param.Name)
+			continue
+		}
+		if recorded == nil {
+			recorded = make(map[string]string, len(params))
+		}
+		recorded[param.Name] = raw
+	}
+
+	return recorded, redacted
+}
+
+// formatArg is the inverse of models.CharmParam.Parse. It returns false
+// if the value contains credentials that must not be recorded.
+func formatArg(param models.CharmParam, value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int:
+		if param.Type == models.ParamEnum && v >= 0 && v < len(param.Options) {
+			return param.Options[v], true
+		}
+		return strconv.Itoa(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case time.Duration:
+		return v.String(), true
+	case *path.Path:
+		if !v.IsSftp() {
+			return v.String(), true
+		}
+		u, err := url.Parse(v.SftpPath())
+		if err != nil {
+			return "", false
+		}
+		if _, hasPassword := u.User.Password(); hasPassword {
+			return "", false
+		}
+		return u.String(), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// replayArgs parses the arguments recorded in a history entry. Secret parameters
+// and parameters that were added since the run are asked for again.
+func replayArgs(charm models.CharmFunc, entry state.HistoryEntry) ([]any, error) {
+	args := make([]any, 0, len(charm.Params))
+	for _, param := range charm.Params {
+		raw, ok := entry.Args[param.Name]
+		if !ok {
+			values, err := console.ParamForm([]models.CharmParam{param})
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, values[0])
+			continue
+		}
+
+		value, err := param.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	return args, nil
+}
+
+// replayFlags merges the recorded arguments of a history entry into the flags, flags win.
+// Redacted parameters have to be passed again as a flag or environment variable.
+func replayFlags(charm models.CharmFunc, entry state.HistoryEntry, flags map[string]string) error {
+	for name, raw := range entry.Args {
+		if _, ok := flags[name]; !ok {
+			flags[name] = raw
+		}
+	}
+
+	for _, param := range charm.Params {
+		if !slices.Contains(entry.Redacted, param.Name) {
+			continue
+		}
+		if _, ok := flags[param.Name]; ok {
+			continue
+		}
+		if _, ok := os.LookupEnv(paramEnvName(param.Name)); ok {
+			continue
+		}
+		return fmt.Errorf("parameter --%s is secret and was not recorded, pass it again (or set %s)", param.Name, paramEnvName(param.Name))
+	}
+	return nil
+}
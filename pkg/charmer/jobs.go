@@ -0,0 +1,87 @@
+package charmer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"time"
+
+	"github.com/ImGajeed76/charmer/pkg/charmer/jobs"
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"github.com/ImGajeed76/charmer/pkg/charmer/path"
+)
+
+// jobCancelTimeout is how long a cancelled job may take to stop before it is killed
+const jobCancelTimeout = 10 * time.Second
+
+// startJob runs the charm as a background job. The job runs the application itself headlessly
+// in a child process, so the output of the charm can be captured without disturbing the TUI.
+// Parameter values are passed as environment variables, so secrets do not show up in the process list.
+// The hooks run in this process, the job only runs the charm through the middleware.
+func startJob(manager *jobs.Manager, charm models.CharmFunc, args []any, hooks Hooks) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("error finding the executable for the background job: %v", err)
+	}
+
+	cmdArgs := []string{"run", charm.Path}
+	if charm.Confirm != nil {
+		// The charm was already confirmed in the TUI
+		cmdArgs = append(cmdArgs, "--"+confirmFlag)
+	}
+	env := jobEnv(os.Environ(), charm, args)
+
+	if hooks.BeforeRun != nil {
+		if err := hooks.BeforeRun(charm, args); err != nil {
+			return err
+		}
+	}
+
+	manager.Start(charm.Path, charmTitle(charm), func(ctx context.Context, output io.Writer) error {
+		cmd := exec.CommandContext(ctx, executable, cmdArgs...)
+		cmd.Env = env
+		cmd.Stdout = output
+		cmd.Stderr = output
+
+		// Interrupt the job, so the charm sees its context cancelled and can clean up
+		cmd.Cancel = func() error {
+			if err := cmd.Process.Signal(os.Interrupt); err != nil {
+				return cmd.Process.Kill()
+			}
+			return nil
+		}
+		cmd.WaitDelay = jobCancelTimeout
+
+		start := time.Now()
+		err := cmd.Run()
+		if hooks.AfterRun != nil {
+			hooks.AfterRun(charm, err, time.Since(start))
+		}
+		return err
+	})
+	return nil
+}
+
+// jobEnv returns the environment of a background job: the environment of the application,
+// the job marker and the arguments. Arguments have their own prefix, so they can't replace the marker.
+func jobEnv(environ []string, charm models.CharmFunc, args []any) []string {
+	env := append(slices.Clone(environ), jobs.Env+"=1")
+	for i, param := range charm.Params {
+		if i < len(args) {
+			env = append(env, jobArgEnvName(param.Name)+"="+jobArg(param, args[i]))
+		}
+	}
+	return env
+}
+
+// jobArg converts a parsed argument back into the raw value passed to the job, including credentials
+func jobArg(param models.CharmParam, value any) string {
+	if p, ok := value.(*path.Path); ok && p.IsSftp() {
+		return p.SftpPath()
+	}
+	raw, _ := formatArg(param, value)
+	return raw
+}
@@ -0,0 +1,274 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Env is set in the environment of charms that run as background jobs
+const Env = "CHARMER_JOB"
+
+// progressPrefix starts the output lines a job reports its progress with
+const progressPrefix = "charmer:progress "
+
+// maxOutput is the number of output bytes kept per job, older output is dropped
+const maxOutput = 256 * 1024
+
+// IsJob reports whether the current process runs as a background job
+func IsJob() bool {
+	return os.Getenv(Env) != ""
+}
+
+// ReportProgress writes a progress line to w. The jobs panel reads it from the
+// output of the job and shows it as a progress bar instead of printing it.
+func ReportProgress(w io.Writer, count, total int64) {
+	_, _ = fmt.Fprintf(w, "%s%d/%d\n", progressPrefix, count, total)
+}
+
+// parseProgress parses a line written by ReportProgress
+func parseProgress(line string) (float64, bool) {
+	value, ok := strings.CutPrefix(line, progressPrefix)
+	if !ok {
+		return 0, false
+	}
+	countText, totalText, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return 0, false
+	}
+	count, err := strconv.ParseInt(countText, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	total, err := strconv.ParseInt(totalText, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if total <= 0 {
+		return 0, true
+	}
+	return min(max(float64(count)/float64(total), 0), 1), true
+}
+
+// Status is the state of a job
+type Status int
+
+const (
+	Running Status = iota
+	Succeeded
+	Failed
+	Cancelled
+)
+
+func (s Status) String() string {
+	switch s {
+	case Running:
+		return "running"
+	case Succeeded:
+		return "succeeded"
+	case Failed:
+		return "failed"
+	case Cancelled:
+		return "cancelled"
+	}
+	return "unknown"
+}
+
+// RunFunc runs a job. Everything written to output is captured,
+// the job has to stop when ctx is cancelled.
+type RunFunc func(ctx context.Context, output io.Writer) error
+
+// Job is a charm running in the background
+type Job struct {
+	ID    int
+	Charm string // Registry path of the charm
+	Title string
+	Start time.Time
+
+	mu          sync.Mutex
+	end         time.Time
+	status      Status
+	err         error
+	output      []byte
+	pending     []byte // Last output line without a newline yet
+	progress    float64
+	hasProgress bool
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// Write captures output of the job. Progress lines are parsed and not captured.
+func (j *Job) Write(p []byte) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.pending = append(j.pending, p...)
+	for {
+		i := bytes.IndexByte(j.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := j.pending[:i+1]
+		if progress, ok := parseProgress(string(line)); ok {
+			j.progress, j.hasProgress = progress, true
+		} else {
+			j.output = append(j.output, line...)
+		}
+		j.pending = j.pending[i+1:]
+	}
+
+	if len(j.output) > maxOutput {
+		j.output = j.output[len(j.output)-maxOutput:]
+	}
+	return len(p), nil
+}
+
+// Output returns the captured output
+func (j *Job) Output() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// An unfinished progress line is not shown
+	if bytes.HasPrefix(j.pending, []byte(progressPrefix)) || bytes.HasPrefix([]byte(progressPrefix), j.pending) {
+		return string(j.output)
+	}
+	return string(j.output) + string(j.pending)
+}
+
+// Progress returns the last progress reported by the job between 0 and 1,
+// and false if the job did not report any progress
+func (j *Job) Progress() (float64, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.progress, j.hasProgress
+}
+
+// Status returns the state of the job
+func (j *Job) Status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+// Err returns the error the job failed with
+func (j *Job) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Duration returns how long the job ran, or runs so far
+func (j *Job) Duration() time.Duration {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status == Running {
+		return time.Since(j.Start)
+	}
+	return j.end.Sub(j.Start)
+}
+
+// Cancel cancels the context of the job
+func (j *Job) Cancel() {
+	j.cancel()
+}
+
+// Done is closed when the job finished
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// finish records the result of the job
+func (j *Job) finish(ctx context.Context, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.end = time.Now()
+	j.err = err
+	switch {
+	case ctx.Err() != nil:
+		j.status = Cancelled
+	case err != nil:
+		j.status = Failed
+	default:
+		j.status = Succeeded
+	}
+	close(j.done)
+}
+
+// Manager starts background jobs and keeps track of them
+type Manager struct {
+	mu     sync.Mutex
+	jobs   []*Job
+	nextID int
+}
+
+// NewManager creates an empty job manager
+func NewManager() *Manager {
+	return &Manager{nextID: 1}
+}
+
+// Start runs a job in the background
+func (m *Manager) Start(charm, title string, run RunFunc) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.mu.Lock()
+	job := &Job{
+		ID:     m.nextID,
+		Charm:  charm,
+		Title:  title,
+		Start:  time.Now(),
+		status: Running,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.nextID++
+	m.jobs = append(m.jobs, job)
+	m.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		err := run(ctx, job)
+		job.finish(ctx, err)
+	}()
+	return job
+}
+
+// Jobs returns all jobs, most recently started first
+func (m *Manager) Jobs() []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]*Job, len(m.jobs))
+	for i, job := range m.jobs {
+		jobs[len(jobs)-1-i] = job
+	}
+	return jobs
+}
+
+// Running returns the number of running jobs
+func (m *Manager) Running() int {
+	running := 0
+	for _, job := range m.Jobs() {
+		if job.Status() == Running {
+			running++
+		}
+	}
+	return running
+}
+
+// Shutdown cancels all running jobs and waits until they finished
+func (m *Manager) Shutdown() {
+	jobs := m.Jobs()
+	for _, job := range jobs {
+		job.Cancel()
+	}
+	for _, job := range jobs {
+		<-job.Done()
+	}
+}
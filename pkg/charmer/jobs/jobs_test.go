@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestJob_Write(t *testing.T) {
+	job := &Job{}
+	_, _ = fmt.Fprint(job, "starting\n")
+	ReportProgress(job, 3, 4)
+	_, _ = fmt.Fprint(job, "charmer:prog")
+	if got := job.Output(); got != "starting\n" {
+		t.Errorf("Output() = %q, want %q", got, "starting\n")
+	}
+
+	_, _ = fmt.Fprint(job, "ress 1/4\nhalf")
+	if got := job.Output(); got != "starting\nhalf" {
+		t.Errorf("Output() = %q, want %q", got, "starting\nhalf")
+	}
+	if progress, ok := job.Progress(); !ok || progress != 0.25 {
+		t.Errorf("Progress() = %v, %v, want 0.25", progress, ok)
+	}
+}
+
+func TestManager(t *testing.T) {
+	manager := NewManager()
+
+	ok := manager.Start("ok", "OK", func(ctx context.Context, output io.Writer) error {
+		_, _ = fmt.Fprintln(output, "done")
+		return nil
+	})
+	failed := manager.Start("fail", "Fail", func(ctx context.Context, output io.Writer) error {
+		return errors.New("boom")
+	})
+	cancelled := manager.Start("wait", "Wait", func(ctx context.Context, output io.Writer) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	<-ok.Done()
+	<-failed.Done()
+	if manager.Running() != 1 {
+		t.Errorf("Running() = %d, want 1", manager.Running())
+	}
+	manager.Shutdown()
+
+	tests := []struct {
+		job  *Job
+		want Status
+	}{
+		{job: ok, want: Succeeded},
+		{job: failed, want: Failed},
+		{job: cancelled, want: Cancelled},
+	}
+	for _, tt := range tests {
+		if got := tt.job.Status(); got != tt.want {
+			t.Errorf("job %s status = %s, want %s", tt.job.Charm, got, tt.want)
+		}
+	}
+
+	if jobs := manager.Jobs(); len(jobs) != 3 || jobs[0] != cancelled || jobs[0].ID != 3 {
+		t.Errorf("Jobs() should list the most recent job first")
+	}
+}
@@ -0,0 +1,78 @@
+package charmer
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ImGajeed76/charmer/pkg/charmer/jobs"
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"github.com/ImGajeed76/charmer/pkg/charmer/state"
+)
+
+func TestJobEnv_ReservedNames(t *testing.T) {
+	charm := models.CharmFunc{
+		Path: "deploy",
+		Params: []models.CharmParam{
+			{Name: "job", Type: models.ParamString},
+			{Name: "theme", Type: models.ParamString},
+		},
+	}
+
+	env := jobEnv([]string{"HOME=/home/user"}, charm, []any{"nightly", "dark"})
+
+	// exec uses the last value of a variable, so look at all of them
+	values := make(map[string][]string)
+	for _, entry := range env {
+		key, value, _ := strings.Cut(entry, "=")
+		values[key] = append(values[key], value)
+	}
+	if got := values[jobs.Env]; !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("%s = %v, want only the job marker", jobs.Env, got)
+	}
+	if got, ok := values[themeEnv]; ok {
+		t.Errorf("%s = %v, the theme parameter must not set it", themeEnv, got)
+	}
+
+	// The child process reads the arguments back
+	for _, entry := range env {
+		key, value, _ := strings.Cut(entry, "=")
+		t.Setenv(key, value)
+	}
+	args, err := resolveArgs(charm.Params, map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []any{"nightly", "dark"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("resolveArgs() = %v, want %v", args, want)
+	}
+}
+
+func TestRunHeadless_Job(t *testing.T) {
+	t.Setenv(jobs.Env, "1")
+
+	var got []any
+	hooked := false
+	options := Options{Hooks: Hooks{
+		BeforeRun: func(charm models.CharmFunc, args []any) error {
+			hooked = true
+			return nil
+		},
+	}}
+	store := &state.Store{}
+	var stdout, stderr bytes.Buffer
+
+	if code := runHeadless(testCharms(&got), []string{"run", "drop", "--yes"}, options, store, &stdout, &stderr); code != exitOK {
+		t.Fatalf("runHeadless() = %d, want %d (stderr: %s)", code, exitOK, stderr.String())
+	}
+	if hooked {
+		t.Error("the job ran the hooks, the application that started it calls them")
+	}
+	if len(store.Recent) != 0 {
+		t.Errorf("the job added the charm to the recent charms: %v", store.Recent)
+	}
+	if entries, _ := store.History(); len(entries) != 1 {
+		t.Errorf("History() has %d entries, want the job recorded once", len(entries))
+	}
+}
@@ -0,0 +1,151 @@
+package charmer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+)
+
+// Handler runs a charm with its parsed arguments
+type Handler func(ctx context.Context, charm models.CharmFunc, args []any) error
+
+// Middleware wraps the execution of a charm. It can decorate the context, run code before and
+// after next, or veto the run by returning an error without calling next.
+//
+//	func RequireVPN(next charmer.Handler) charmer.Handler {
+//		return func(ctx context.Context, charm models.CharmFunc, args []any) error {
+//			if charm.HasTag("network") && !vpnConnected() {
+//				return errors.New("connect to the VPN first")
+//			}
+//			return next(ctx, charm, args)
+//		}
+//	}
+type Middleware func(next Handler) Handler
+
+// chain wraps handler in the middleware, the first middleware is the outermost
+func chain(handler Handler, middleware []Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// Timing writes how long each charm run took to w
+func Timing(w io.Writer) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, charm models.CharmFunc, args []any) error {
+			start := time.Now()
+			err := next(ctx, charm, args)
+			_, _ = fmt.Fprintf(w, "%s finished in %s\n", charm.Path, time.Since(start).Round(time.Millisecond))
+			return err
+		}
+	}
+}
+
+// AuditEntry is a line of the audit log written by AuditLog
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Host     string    `json:"host"`
+	Charm    string    `json:"charm"`
+	Duration float64   `json:"duration_seconds"`
+	Status   string    `json:"status"` // ok, error, cancelled or panic
+	Error    string    `json:"error,omitempty"`
+}
+
+// AuditLog appends an AuditEntry as a JSON line to the file at path for every charm run.
+// Argument values are not logged, as they may contain secrets.
+// The charm does not run if the audit log cannot be opened.
+func AuditLog(path string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, charm models.CharmFunc, args []any) error {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return fmt.Errorf("error creating audit log directory: %v", err)
+			}
+			file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+			if err != nil {
+				return fmt.Errorf("error opening audit log: %v", err)
+			}
+			defer file.Close()
+
+			entry := AuditEntry{
+				Time:  time.Now(),
+				User:  currentUser(),
+				Charm: charm.Path,
+			}
+			entry.Host, _ = os.Hostname()
+
+			err = next(ctx, charm, args)
+			entry.Duration = time.Since(entry.Time).Seconds()
+			entry.Status = runStatus(err)
+			if err != nil {
+				entry.Error = err.Error()
+			}
+
+			line, marshalErr := json.Marshal(entry)
+			if marshalErr != nil {
+				return errors.Join(err, marshalErr)
+			}
+			if _, writeErr := file.Write(append(line, '\n')); writeErr != nil {
+				return errors.Join(err, fmt.Errorf("error writing audit log: %v", writeErr))
+			}
+			return err
+		}
+	}
+}
+
+// requireEnv vetoes the run if an environment variable declared by @Requires env=NAME is not set.
+// It is always the innermost middleware, so the other middleware sees its errors.
+func requireEnv(next Handler) Handler {
+	return func(ctx context.Context, charm models.CharmFunc, args []any) error {
+		var missing []string
+		for _, name := range charm.RequiredEnv {
+			if os.Getenv(name) == "" {
+				missing = append(missing, name)
+			}
+		}
+
+		switch len(missing) {
+		case 0:
+			return next(ctx, charm, args)
+		case 1:
+			return fmt.Errorf("missing required environment variable %s", missing[0])
+		default:
+			return fmt.Errorf("missing required environment variables %s", strings.Join(missing, ", "))
+		}
+	}
+}
+
+// runStatus classifies the error returned by a charm run
+func runStatus(err error) string {
+	var panicErr *PanicError
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.As(err, &panicErr):
+		return "panic"
+	case errors.Is(err, context.Canceled):
+		return "cancelled"
+	}
+	return "error"
+}
+
+// currentUser returns the name of the user running the process
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
@@ -0,0 +1,120 @@
+package charmer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+)
+
+func TestExecute_Middleware(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, charm models.CharmFunc, args []any) error {
+				calls = append(calls, name+" before")
+				err := next(ctx, charm, args)
+				calls = append(calls, name+" after")
+				return err
+			}
+		}
+	}
+	veto := func(next Handler) Handler {
+		return func(ctx context.Context, charm models.CharmFunc, args []any) error {
+			if charm.HasTag("network") {
+				return errors.New("not connected to the VPN")
+			}
+			return next(ctx, charm, args)
+		}
+	}
+
+	charm := models.CharmFunc{Path: "ping", Execute: func(ctx context.Context, args []any) error {
+		calls = append(calls, "charm")
+		return nil
+	}}
+	if err := execute(charm, nil, []Middleware{trace("outer"), trace("inner"), veto}); err != nil {
+		t.Fatalf("execute() error = %v", err)
+	}
+	want := []string{"outer before", "inner before", "charm", "inner after", "outer after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	calls = nil
+	charm.Tags = []string{"network"}
+	if err := execute(charm, nil, []Middleware{veto}); err == nil || len(calls) != 0 {
+		t.Errorf("execute() = %v with calls %v, want the run to be vetoed", err, calls)
+	}
+}
+
+func TestExecute_RequiredEnv(t *testing.T) {
+	t.Setenv("CHARMER_TEST_SET", "1")
+	t.Setenv("CHARMER_TEST_EMPTY", "")
+
+	ran := false
+	charm := models.CharmFunc{
+		Path:        "deploy",
+		RequiredEnv: []string{"CHARMER_TEST_SET", "CHARMER_TEST_EMPTY", "CHARMER_TEST_UNSET"},
+		Execute: func(ctx context.Context, args []any) error {
+			ran = true
+			return nil
+		},
+	}
+
+	err := execute(charm, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "CHARMER_TEST_EMPTY, CHARMER_TEST_UNSET") || ran {
+		t.Errorf("execute() error = %v, ran = %v, want the missing variables to be reported", err, ran)
+	}
+
+	charm.RequiredEnv = charm.RequiredEnv[:1]
+	if err := execute(charm, nil, nil); err != nil || !ran {
+		t.Errorf("execute() error = %v, ran = %v, want the charm to run", err, ran)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	var got []any
+	charms := testCharms(&got)
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	middleware := []Middleware{AuditLog(path)}
+
+	for _, name := range []string{"deploy/staging", "fail", "panic"} {
+		_ = execute(charms[name], []any{"secret"}, middleware)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), "secret") {
+			t.Errorf("audit log contains argument values: %s", scanner.Text())
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	want := [][2]string{{"deploy/staging", "ok"}, {"fail", "error"}, {"panic", "panic"}}
+	if len(entries) != len(want) {
+		t.Fatalf("audit log has %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry.Charm != want[i][0] || entry.Status != want[i][1] {
+			t.Errorf("entry %d = %s %s, want %s %s", i, entry.Charm, entry.Status, want[i][0], want[i][1])
+		}
+	}
+}
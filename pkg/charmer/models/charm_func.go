@@ -1,10 +1,39 @@
 package models
 
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// ExecuteFunc runs a charm. The generator wraps every supported charm signature
+// in an ExecuteFunc, args holds the parsed parameter values in declaration order.
+type ExecuteFunc func(ctx context.Context, args []any) error
+
 type CharmFunc struct {
 	Name        string
 	Doc         string
-	Execute     interface{}
+	Execute     ExecuteFunc
 	Path        string
 	Title       string
 	Description string
+	Params      []CharmParam
+	Order       int    // Entries are sorted by Order first, then alphabetically
+	Hidden      bool   // Hidden charms are not listed, but can still be run headlessly
+	Deprecated  string // Deprecation message, empty if the charm is not deprecated
+	Confirm     *Confirmation
+	Tags        []string // Lowercase tags declared by @Tags, used for filtering
+	RequiredEnv []string // Environment variables declared by @Requires env=NAME, checked before the charm runs
+}
+
+// Confirmation describes the gate shown before a destructive charm runs
+type Confirmation struct {
+	Message string
+	Phrase  string // Phrase the user has to type, empty for a yes/no confirmation
+}
+
+// HasTag reports whether the charm is tagged with tag, ignoring case and a leading #
+func (c CharmFunc) HasTag(tag string) bool {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	return slices.Contains(c.Tags, tag)
 }
@@ -0,0 +1,11 @@
+package models
+
+// CharmGroup holds the menu metadata of a charm directory, declared by a
+// package doc comment with a @Group annotation (usually in doc.go)
+type CharmGroup struct {
+	Path        string
+	Title       string
+	Description string
+	Icon        string
+	Order       int // Entries are sorted by Order first, then alphabetically
+}
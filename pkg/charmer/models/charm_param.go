@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"github.com/ImGajeed76/charmer/pkg/charmer/path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParamType identifies the Go type of a charm parameter
+type ParamType string
+
+const (
+	ParamString   ParamType = "string"
+	ParamInt      ParamType = "int"
+	ParamFloat    ParamType = "float"
+	ParamBool     ParamType = "bool"
+	ParamDuration ParamType = "duration"
+	ParamEnum     ParamType = "enum"
+	ParamPath     ParamType = "path"
+)
+
+// CharmParam describes a single parameter of a charm function.
+// Prompt, Default, Regex and Secret come from the @Param annotation.
+type CharmParam struct {
+	Name    string
+	Type    ParamType
+	Prompt  string
+	Default string
+	Regex   string
+	Options []string // Enum options, in declaration order
+	Secret  bool     // Secret values are never written to the run history
+}
+
+// Parse converts a raw string value into the Go value expected by the charm.
+// Enum parameters are returned as the index of the matching option.
+func (p CharmParam) Parse(raw string) (any, error) {
+	switch p.Type {
+	case ParamString:
+		return raw, nil
+	case ParamInt:
+		value, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a whole number", p.Name)
+		}
+		return value, nil
+	case ParamFloat:
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", p.Name)
+		}
+		return value, nil
+	case ParamBool:
+		value, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", p.Name)
+		}
+		return value, nil
+	case ParamDuration:
+		value, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a duration like 30s or 5m", p.Name)
+		}
+		return value, nil
+	case ParamEnum:
+		for i, option := range p.Options {
+			if option == raw {
+				return i, nil
+			}
+		}
+		return nil, fmt.Errorf("%s must be one of: %s", p.Name, strings.Join(p.Options, ", "))
+	case ParamPath:
+		// Parse instead of New, New exits the program on invalid paths
+		value, err := path.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a valid path: %w", p.Name, err)
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("unsupported parameter type '%s'", p.Type)
+	}
+}
+
+// Label returns the prompt shown for the parameter, falling back to its name
+func (p CharmParam) Label() string {
+	if p.Prompt != "" {
+		return p.Prompt
+	}
+	return p.Name
+}
@@ -0,0 +1,137 @@
+package charmer
+
+import (
+	"strings"
+	"time"
+
+	constants "github.com/ImGajeed76/charmer/internal"
+	"github.com/ImGajeed76/charmer/pkg/charmer/console"
+	"github.com/ImGajeed76/charmer/pkg/charmer/jobs"
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"github.com/ImGajeed76/charmer/pkg/charmer/state"
+	"github.com/ImGajeed76/charmer/pkg/charmer/theme"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Options configures RunWithOptions. The zero value gives the behavior of Run.
+type Options struct {
+	// AppName is shown in the top bar and names the config directory favorites and the keymap are stored in.
+	// The top bar shows "Charmer" and the config directory is named after the executable if it is empty.
+	AppName string
+	// Version is shown after the app name. It defaults to the Charmer version if AppName is empty.
+	Version string
+	// Banner is an optional line shown below the app name, e.g. a tagline
+	Banner string
+
+	// StartPath is the folder the charm selector opens in, e.g. "deploy/staging"
+	StartPath string
+	// Session returns to the charm selector after a charm finished, see RunSession
+	Session bool
+	// DisableAltScreen renders the charm selector inline instead of in the alternate screen buffer
+	DisableAltScreen bool
+	// DisableMouse turns off mouse support, so the terminal's own text selection works
+	DisableMouse bool
+	// BackgroundJobs lets users run charms as background jobs from the charm selector and follow
+	// them in the jobs panel. It implies Session, so the selector stays open while jobs run.
+	BackgroundJobs bool
+
+	// Groups holds the directory metadata shown for folders in the charm selector,
+	// usually the RegisteredGroups variable of the generated registry
+	Groups map[string]models.CharmGroup
+	// KeyMap holds the key bindings of the charm selector, nil uses console.DefaultSelectorKeyMap.
+	// Users can override them in keymap.json in the config directory, see console.KeyMapConfig.
+	KeyMap *console.SelectorKeyMap
+	// Theme styles the charm selector and all console widgets. If it is nil, a light or dark
+	// theme is picked from the terminal background. Users can override it with CHARMER_THEME.
+	Theme *theme.Theme
+	// CrashReportDir is the directory crash reports are written to when a charm panics.
+	// Crash reports are disabled if it is empty.
+	CrashReportDir string
+
+	Hooks Hooks
+	// Middleware wraps every charm run, both from the charm selector and headlessly.
+	// The first middleware is the outermost, see Timing and AuditLog for built-in middleware.
+	Middleware []Middleware
+}
+
+// Hooks are called around charm runs, both from the charm selector and headlessly.
+// For background jobs they are called by the application that started the job, AfterRun once the job finished.
+type Hooks struct {
+	// BeforeRun is called after the parameters were collected and the charm was confirmed.
+	// Returning an error skips the charm and reports the error in its place.
+	BeforeRun func(charm models.CharmFunc, args []any) error
+	// AfterRun is called after the charm returned, err is the error the charm returned
+	AfterRun func(charm models.CharmFunc, err error, duration time.Duration)
+	// OnExit is called before the charm selector exits, and after the command in headless mode
+	OnExit func()
+}
+
+// title returns the text shown in the top bar
+func (o Options) title() string {
+	name, version := o.AppName, o.Version
+	if name == "" {
+		name = "Charmer"
+		if version == "" {
+			version = constants.Version
+		}
+	}
+
+	if version == "" {
+		return name
+	}
+	return name + " - v" + strings.TrimPrefix(version, "v")
+}
+
+// configName returns the name of the config directory
+func (o Options) configName() string {
+	if o.AppName != "" {
+		return o.AppName
+	}
+	return programName()
+}
+
+// keyMap returns the configured key bindings or the defaults
+func (o Options) keyMap() console.SelectorKeyMap {
+	if o.KeyMap != nil {
+		return *o.KeyMap
+	}
+	return console.DefaultSelectorKeyMap()
+}
+
+// programOptions returns the bubbletea options of the charm selector
+func (o Options) programOptions() []tea.ProgramOption {
+	var opts []tea.ProgramOption
+	if !o.DisableAltScreen {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	if !o.DisableMouse {
+		opts = append(opts, tea.WithMouseAllMotion())
+	}
+	return opts
+}
+
+// runCharm runs the charm through the middleware between the BeforeRun and AfterRun hooks,
+// records it in the run history of the store and returns how long it took.
+// Background jobs skip the hooks, the application that started the job calls them.
+func (o Options) runCharm(charm models.CharmFunc, args []any, store *state.Store) (time.Duration, error) {
+	if jobs.IsJob() {
+		start := time.Now()
+		err := execute(charm, args, append([]Middleware{recordHistory(store)}, o.Middleware...))
+		return time.Since(start), err
+	}
+
+	if o.Hooks.BeforeRun != nil {
+		if err := o.Hooks.BeforeRun(charm, args); err != nil {
+			return 0, err
+		}
+	}
+
+	start := time.Now()
+	err := execute(charm, args, append([]Middleware{recordHistory(store)}, o.Middleware...))
+	duration := time.Since(start)
+
+	if o.Hooks.AfterRun != nil {
+		o.Hooks.AfterRun(charm, err, duration)
+	}
+	return duration, err
+}
@@ -0,0 +1,88 @@
+package charmer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	constants "github.com/ImGajeed76/charmer/internal"
+	"github.com/ImGajeed76/charmer/pkg/charmer/models"
+	"github.com/ImGajeed76/charmer/pkg/charmer/state"
+)
+
+func TestOptions_Title(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		want    string
+	}{
+		{name: "Defaults", options: Options{}, want: "Charmer - v" + constants.Version},
+		{name: "App name only", options: Options{AppName: "Ops"}, want: "Ops"},
+		{name: "App name and version", options: Options{AppName: "Ops", Version: "v1.2.0"}, want: "Ops - v1.2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.title(); got != tt.want {
+				t.Errorf("title() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartPath(t *testing.T) {
+	charms := map[string]models.CharmFunc{"deploy/staging/Run": {Path: "deploy/staging/Run"}}
+
+	tests := []struct {
+		folder string
+		want   string
+	}{
+		{folder: "", want: ""},
+		{folder: "/deploy/staging", want: "deploy/staging/"},
+		{folder: "deploy/", want: "deploy/"},
+		{folder: "missing", want: ""},
+		{folder: "deploy/staging/Run", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := startPath(charms, tt.folder); got != tt.want {
+			t.Errorf("startPath(%q) = %q, want %q", tt.folder, got, tt.want)
+		}
+	}
+}
+
+func TestOptions_Hooks(t *testing.T) {
+	var got []any
+	charms := testCharms(&got)
+
+	var ran []string
+	var afterErr error
+	options := Options{Hooks: Hooks{
+		BeforeRun: func(charm models.CharmFunc, args []any) error {
+			if charm.Path == "drop" {
+				return errors.New("not during business hours")
+			}
+			return nil
+		},
+		AfterRun: func(charm models.CharmFunc, err error, duration time.Duration) {
+			ran = append(ran, charm.Path)
+			afterErr = err
+		},
+	}}
+
+	var stdout, stderr bytes.Buffer
+	if code := runHeadless(charms, []string{"run", "drop", "--yes"}, options, &state.Store{}, &stdout, &stderr); code != exitError {
+		t.Fatalf("runHeadless() = %d, want %d", code, exitError)
+	}
+	if got != nil || len(ran) != 0 {
+		t.Fatalf("charm should not run when BeforeRun fails, ran %v", ran)
+	}
+
+	if code := runHeadless(charms, []string{"run", "fail"}, options, &state.Store{}, &stdout, &stderr); code != exitError {
+		t.Fatalf("runHeadless() = %d, want %d", code, exitError)
+	}
+	if len(ran) != 1 || ran[0] != "fail" || afterErr == nil {
+		t.Errorf("AfterRun called for %v with %v, want fail with its error", ran, afterErr)
+	}
+}
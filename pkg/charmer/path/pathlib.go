@@ -13,7 +13,6 @@ import (
 	pathurllocal "github.com/ImGajeed76/charmer/pkg/charmer/path/operations/urllocal"
 	pathurlsftp "github.com/ImGajeed76/charmer/pkg/charmer/path/operations/urlsftp"
 	sftpmanager "github.com/ImGajeed76/charmer/pkg/charmer/sftp"
-	"log"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -24,10 +23,21 @@ import (
 	"unicode"
 )
 
+// New creates a path from a local path, an sftp:// URL or an http(s):// URL.
+// It returns nil if the path is empty or invalid, use Parse to get the error.
 func New(path string, parameter ...*SFTPConfig) *Path {
-	if path == "" {
+	newPath, err := Parse(path, parameter...)
+	if err != nil {
 		return nil
 	}
+	return newPath
+}
+
+// Parse creates a path like New, but returns the error if the path is empty or invalid
+func Parse(path string, parameter ...*SFTPConfig) (*Path, error) {
+	if path == "" {
+		return nil, errors.New("empty path")
+	}
 
 	// Get config
 	var sftpConf *SFTPConfig = nil
@@ -41,7 +51,7 @@ func New(path string, parameter ...*SFTPConfig) *Path {
 	if strings.HasPrefix(path, "sftp://") && sftpConf == nil {
 		u, err := url.Parse(path)
 		if err != nil {
-			return nil
+			return nil, err
 		}
 
 		// Extract authentication info
@@ -75,19 +85,17 @@ func New(path string, parameter ...*SFTPConfig) *Path {
 
 		err = newPath.Validate()
 		if err != nil {
-			log.Fatal(err)
-			return nil
+			return nil, err
 		}
 
-		return newPath
+		return newPath, nil
 	}
 
 	// Handle URLs
 	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
 		u, err := url.Parse(path)
 		if err != nil {
-			log.Fatal(err)
-			return nil
+			return nil, err
 		}
 
 		newPath := &Path{
@@ -97,18 +105,16 @@ func New(path string, parameter ...*SFTPConfig) *Path {
 
 		err = newPath.Validate()
 		if err != nil {
-			log.Fatal(err)
-			return nil
+			return nil, err
 		}
 
-		return newPath
+		return newPath, nil
 	}
 
 	// Use config if available
 	if sftpConf != nil {
 		if !strings.HasPrefix(path, "/") {
-			log.Fatal("SFTP path must be absolute")
-			return nil
+			return nil, errors.New("SFTP path must be absolute")
 		}
 
 		newPath := &Path{
@@ -122,11 +128,10 @@ func New(path string, parameter ...*SFTPConfig) *Path {
 
 		err := newPath.Validate()
 		if err != nil {
-			log.Fatal(err)
-			return nil
+			return nil, err
 		}
 
-		return newPath
+		return newPath, nil
 	}
 
 	// If path is relative, convert to absolute
@@ -136,8 +141,7 @@ func New(path string, parameter ...*SFTPConfig) *Path {
 		var err error
 		absPath, err = filepath.Abs(path)
 		if err != nil {
-			log.Fatal(err)
-			return nil
+			return nil, err
 		}
 	}
 
@@ -148,11 +152,10 @@ func New(path string, parameter ...*SFTPConfig) *Path {
 
 	err := newPath.Validate()
 	if err != nil {
-		log.Fatal(err)
-		return nil
+		return nil, err
 	}
 
-	return newPath
+	return newPath, nil
 }
 
 func (p *Path) ConnectionDetails() (*sftpmanager.ConnectionDetails, error) {
@@ -732,7 +735,10 @@ func (p *Path) List() ([]*Path, error) {
 		// convert list of strings to list of Paths
 		paths := make([]*Path, len(list))
 		for i, path := range list {
-			paths[i] = New(path)
+			paths[i], err = Parse(path)
+			if err != nil {
+				return nil, &pathmodels.PathError{Op: "list", Path: path, Err: err}
+			}
 		}
 		return paths, nil
 	}
@@ -0,0 +1,185 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MaxHistory is the number of charm runs kept in the history file
+const MaxHistory = 500
+
+// HistoryEntry records a single charm run
+type HistoryEntry struct {
+	ID       int               `json:"id"`
+	Charm    string            `json:"charm"`
+	Args     map[string]string `json:"args,omitempty"`     // Raw parameter values by name, secrets excluded
+	Redacted []string          `json:"redacted,omitempty"` // Secret parameters whose values were not recorded
+	Start    time.Time         `json:"start"`
+	End      time.Time         `json:"end"`
+	Status   string            `json:"status"` // ok, error, cancelled or panic
+	Error    string            `json:"error,omitempty"`
+}
+
+// Duration returns how long the run took
+func (e HistoryEntry) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// historyPath returns the path of the history file next to the state file
+func (s *Store) historyPath() string {
+	if s.path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(s.path), "history.jsonl")
+}
+
+// History returns the recorded charm runs, most recent first.
+// The history file is only read again when it changed, e.g. by a background job.
+func (s *Store) History() ([]HistoryEntry, error) {
+	if err := s.loadHistory(); err != nil {
+		return nil, err
+	}
+
+	entries := make([]HistoryEntry, len(s.history))
+	for i, entry := range s.history {
+		entries[len(entries)-1-i] = entry
+	}
+	return entries, nil
+}
+
+// HistoryEntry returns the recorded charm run with the given ID
+func (s *Store) HistoryEntry(id int) (HistoryEntry, bool, error) {
+	if err := s.loadHistory(); err != nil {
+		return HistoryEntry{}, false, err
+	}
+
+	for _, entry := range s.history {
+		if entry.ID == id {
+			return entry, true, nil
+		}
+	}
+	return HistoryEntry{}, false, nil
+}
+
+// AddHistory assigns the next ID to the entry and appends it to the history file.
+// Background jobs append to the same file, so it is locked and read again before the ID is assigned.
+// Stores that were not loaded from a file keep their history in memory only.
+func (s *Store) AddHistory(entry HistoryEntry) (HistoryEntry, error) {
+	if path := s.historyPath(); path != "" {
+		unlock, err := s.lock()
+		if err != nil {
+			return entry, err
+		}
+		defer unlock()
+
+		// The modification time may not have changed if another process appended just now
+		s.historyStat = nil
+	}
+	if err := s.loadHistory(); err != nil {
+		return entry, err
+	}
+
+	entry.ID = 1
+	if len(s.history) > 0 {
+		entry.ID = s.history[len(s.history)-1].ID + 1
+	}
+	s.history = append(s.history, entry)
+
+	path := s.historyPath()
+	if path == "" {
+		if len(s.history) > MaxHistory {
+			s.history = s.history[len(s.history)-MaxHistory:]
+		}
+		return entry, nil
+	}
+
+	// Rewrite the file once it grew too long, otherwise only append the new entry
+	if len(s.history) > MaxHistory {
+		s.history = s.history[len(s.history)-MaxHistory:]
+		return entry, s.writeHistory(path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return entry, fmt.Errorf("error creating state directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return entry, fmt.Errorf("error opening history file: %v", err)
+	}
+	defer file.Close()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return entry, err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return entry, fmt.Errorf("error writing history file: %v", err)
+	}
+	return entry, nil
+}
+
+// loadHistory reads the history file if it changed since it was last read
+func (s *Store) loadHistory() error {
+	path := s.historyPath()
+	if path == "" {
+		return nil
+	}
+
+	stat, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.history, s.historyStat = nil, nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading history file: %v", err)
+	}
+	if s.historyStat != nil && stat.Size() == s.historyStat.Size() && stat.ModTime().Equal(s.historyStat.ModTime()) {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading history file: %v", err)
+	}
+
+	s.history = nil
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		// A crash while appending can leave a truncated last line behind, skip it
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		s.history = append(s.history, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading history file: %v", err)
+	}
+
+	s.historyStat = stat
+	return nil
+}
+
+// writeHistory replaces the history file with the entries in memory
+func (s *Store) writeHistory(path string) error {
+	var data bytes.Buffer
+	for _, entry := range s.history {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		data.Write(append(line, '\n'))
+	}
+
+	if err := writeFile(path, data.Bytes(), 0600); err != nil {
+		return fmt.Errorf("error writing history file: %v", err)
+	}
+	return nil
+}
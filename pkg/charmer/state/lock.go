@@ -0,0 +1,34 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lock takes the lock shared by all files of the store, so processes using the same store,
+// like the menu and its background jobs, don't overwrite each other's changes
+func (s *Store) lock() (func(), error) {
+	return lockFile(filepath.Join(filepath.Dir(s.path), "state.lock"))
+}
+
+// lockFile takes an exclusive lock on the file at path, creating it if needed.
+// It blocks until other processes released the lock and returns a function releasing it.
+func lockFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("error creating state directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening lock file: %v", err)
+	}
+	if err := lock(file); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("error locking %s: %v", path, err)
+	}
+
+	return func() {
+		_ = unlock(file)
+		_ = file.Close()
+	}, nil
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// MaxPromptHistory is the number of values remembered per prompt
+const MaxPromptHistory = 50
+
+// promptsPath returns the path of the prompt history file next to the state file
+func (s *Store) promptsPath() string {
+	if s.path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(s.path), "prompts.json")
+}
+
+// PromptHistory returns the values entered into the prompt with the given key, most recent first
+func (s *Store) PromptHistory(key string) ([]string, error) {
+	if err := s.loadPrompts(); err != nil {
+		return nil, err
+	}
+	return slices.Clone(s.prompts[key]), nil
+}
+
+// AddPromptHistory moves the value to the front of the history of the prompt with the given key.
+// The file is locked and read again first, so values entered by background jobs are kept.
+func (s *Store) AddPromptHistory(key, value string) error {
+	path := s.promptsPath()
+	if path != "" {
+		unlock, err := s.lock()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+	if err := s.loadPrompts(); err != nil {
+		return err
+	}
+
+	values := s.prompts[key]
+	if i := slices.Index(values, value); i >= 0 {
+		values = slices.Delete(values, i, i+1)
+	}
+	values = append([]string{value}, values...)
+	if len(values) > MaxPromptHistory {
+		values = values[:MaxPromptHistory]
+	}
+	s.prompts[key] = values
+
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.prompts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error writing prompt history file: %v", err)
+	}
+	return nil
+}
+
+// loadPrompts reads the prompt history file. Stores that were not loaded from a file keep it in memory only.
+func (s *Store) loadPrompts() error {
+	path := s.promptsPath()
+	if path == "" {
+		if s.prompts == nil {
+			s.prompts = make(map[string][]string)
+		}
+		return nil
+	}
+
+	prompts := make(map[string][]string)
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading prompt history file: %v", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &prompts); err != nil {
+			return fmt.Errorf("error parsing prompt history file %s: %v", path, err)
+		}
+	}
+
+	s.prompts = prompts
+	return nil
+}
@@ -0,0 +1,215 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// MaxRecent is the number of recently used charms that are remembered
+const MaxRecent = 10
+
+// Store holds the favorites, recently used charms, run history and prompt history of the current user.
+// Charms are referenced by their registry path.
+type Store struct {
+	Favorites []string `json:"favorites"`
+	Recent    []string `json:"recent"` // Most recently used first
+
+	path string
+
+	// Favorites and Recent as last read from or written to the file. Save merges the changes
+	// made since into the file, so background jobs and the menu don't undo each other's changes.
+	savedFavorites []string
+	savedRecent    []string
+
+	// The run history is stored in its own file and read again when the file changed, see History
+	history     []HistoryEntry
+	historyStat os.FileInfo
+
+	// Values entered into prompts by prompt key, stored in their own file, see PromptHistory
+	prompts map[string][]string
+}
+
+// Dir returns the directory of the given application in the user config directory,
+// e.g. ~/.config/<app> on Linux
+func Dir(app string) (string, error) {
+	if app == "" {
+		return "", fmt.Errorf("application name cannot be empty")
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding user config directory: %v", err)
+	}
+	return filepath.Join(configDir, app), nil
+}
+
+// Open loads the store of the given application from state.json in its Dir.
+// A missing file results in an empty store.
+func Open(app string) (*Store, error) {
+	dir, err := Dir(app)
+	if err != nil {
+		return nil, err
+	}
+	return Load(filepath.Join(dir, "state.json"))
+}
+
+// Load loads the store from a file. A missing file results in an empty store,
+// which is written to the file on Save.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading state file: %v", err)
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("error parsing state file %s: %v", path, err)
+	}
+	store.savedFavorites, store.savedRecent = slices.Clone(store.Favorites), slices.Clone(store.Recent)
+	return store, nil
+}
+
+// Save writes the store to its file. It does nothing for stores that were not loaded from a file.
+// The file is locked and read again first, the favorites and recent charms changed since the store
+// was loaded are merged into it, so changes saved by other processes in the meantime are kept.
+func (s *Store) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// A broken state file is replaced
+	current, err := Load(s.path)
+	if err != nil {
+		current = &Store{}
+	}
+	s.Favorites = mergeFavorites(current.Favorites, s.savedFavorites, s.Favorites)
+	s.Recent = mergeRecent(current.Recent, s.savedRecent, s.Recent)
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("error writing state file: %v", err)
+	}
+
+	s.savedFavorites, s.savedRecent = slices.Clone(s.Favorites), slices.Clone(s.Recent)
+	return nil
+}
+
+// mergeFavorites applies the favorites added and removed since saved to the current favorites
+func mergeFavorites(current, saved, favorites []string) []string {
+	merged := make([]string, 0, len(current)+len(favorites))
+	for _, charmPath := range current {
+		removed := slices.Contains(saved, charmPath) && !slices.Contains(favorites, charmPath)
+		if !removed {
+			merged = append(merged, charmPath)
+		}
+	}
+	for _, charmPath := range favorites {
+		if !slices.Contains(merged, charmPath) && !slices.Contains(saved, charmPath) {
+			merged = append(merged, charmPath)
+		}
+	}
+	return merged
+}
+
+// mergeRecent moves the charms used since saved to the front of the current recent charms.
+// AddRecent only moves charms to the front, so the used ones are the shortest prefix
+// of recent after which the saved order continues.
+func mergeRecent(current, saved, recent []string) []string {
+	used := recent
+	for k := range recent {
+		rest := slices.DeleteFunc(slices.Clone(saved), func(charmPath string) bool {
+			return slices.Contains(recent[:k], charmPath)
+		})
+		if len(recent[k:]) <= len(rest) && slices.Equal(recent[k:], rest[:len(recent)-k]) {
+			used = recent[:k]
+			break
+		}
+	}
+
+	merged := slices.Clone(used)
+	for _, charmPath := range current {
+		if !slices.Contains(merged, charmPath) {
+			merged = append(merged, charmPath)
+		}
+	}
+	if len(merged) > MaxRecent {
+		merged = merged[:MaxRecent]
+	}
+	return merged
+}
+
+// writeFile replaces the file at path with data. It writes to a temporary file first, so a crash
+// never leaves a truncated file behind, and other processes never write to the same temporary file.
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), perm)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// IsFavorite reports whether the charm is a favorite
+func (s *Store) IsFavorite(charmPath string) bool {
+	return slices.Contains(s.Favorites, charmPath)
+}
+
+// ToggleFavorite adds the charm to the favorites or removes it, and returns whether it is a favorite now
+func (s *Store) ToggleFavorite(charmPath string) bool {
+	if i := slices.Index(s.Favorites, charmPath); i >= 0 {
+		s.Favorites = slices.Delete(s.Favorites, i, i+1)
+		return false
+	}
+	s.Favorites = append(s.Favorites, charmPath)
+	return true
+}
+
+// AddRecent moves the charm to the front of the recently used charms
+func (s *Store) AddRecent(charmPath string) {
+	if i := slices.Index(s.Recent, charmPath); i >= 0 {
+		s.Recent = slices.Delete(s.Recent, i, i+1)
+	}
+	s.Recent = append([]string{charmPath}, s.Recent...)
+	if len(s.Recent) > MaxRecent {
+		s.Recent = s.Recent[:MaxRecent]
+	}
+}
+
+// Last returns the most recently used charm
+func (s *Store) Last() (string, bool) {
+	if len(s.Recent) == 0 {
+		return "", false
+	}
+	return s.Recent[0], true
+}
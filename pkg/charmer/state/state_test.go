@@ -0,0 +1,296 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app", "state.json")
+
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Last(); ok {
+		t.Error("Last() of an empty store should not return a charm")
+	}
+
+	store.ToggleFavorite("db/Backup")
+	store.ToggleFavorite("deploy/Staging")
+	store.AddRecent("db/Backup")
+	store.AddRecent("deploy/Staging")
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"db/Backup", "deploy/Staging"}; !reflect.DeepEqual(loaded.Favorites, want) {
+		t.Errorf("Favorites = %v, want %v", loaded.Favorites, want)
+	}
+	if last, _ := loaded.Last(); last != "deploy/Staging" {
+		t.Errorf("Last() = %q, want %q", last, "deploy/Staging")
+	}
+}
+
+func TestStore_SaveMerges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	initial := &Store{path: path}
+	initial.ToggleFavorite("db/Backup")
+	initial.ToggleFavorite("db/Restore")
+	initial.AddRecent("db/Backup")
+	if err := initial.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two processes load the same state, then save their own changes
+	menu, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	menu.ToggleFavorite("db/Restore")
+	menu.ToggleFavorite("deploy/Staging")
+	job.AddRecent("deploy/Production")
+	if err := job.Save(); err != nil {
+		t.Fatal(err)
+	}
+	menu.AddRecent("db/Restore")
+	if err := menu.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"db/Backup", "deploy/Staging"}; !reflect.DeepEqual(loaded.Favorites, want) {
+		t.Errorf("Favorites = %v, want %v", loaded.Favorites, want)
+	}
+	if want := []string{"db/Restore", "deploy/Production", "db/Backup"}; !reflect.DeepEqual(loaded.Recent, want) {
+		t.Errorf("Recent = %v, want %v", loaded.Recent, want)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("temporary file %s was left behind", entry.Name())
+		}
+	}
+}
+
+func TestStore_ToggleFavorite(t *testing.T) {
+	store := &Store{}
+	if !store.ToggleFavorite("a") || !store.IsFavorite("a") {
+		t.Error("first toggle should add the favorite")
+	}
+	if store.ToggleFavorite("a") || store.IsFavorite("a") {
+		t.Error("second toggle should remove the favorite")
+	}
+}
+
+func TestStore_AddRecent(t *testing.T) {
+	store := &Store{}
+	for i := 0; i < MaxRecent+2; i++ {
+		store.AddRecent(fmt.Sprintf("charm%d", i))
+	}
+	store.AddRecent("charm5")
+
+	if len(store.Recent) != MaxRecent {
+		t.Fatalf("len(Recent) = %d, want %d", len(store.Recent), MaxRecent)
+	}
+	if want := []string{"charm5", "charm11", "charm10"}; !reflect.DeepEqual(store.Recent[:3], want) {
+		t.Errorf("Recent = %v, want it to start with %v", store.Recent, want)
+	}
+}
+
+func TestStore_History(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, charm := range []string{"db/Backup", "deploy/Staging"} {
+		if _, err := store.AddHistory(HistoryEntry{Charm: charm, Args: map[string]string{"name": "web"}, Status: "ok"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := loaded.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].ID != 2 || entries[0].Charm != "deploy/Staging" {
+		t.Fatalf("History() = %+v, want deploy/Staging with ID 2 first", entries)
+	}
+
+	entry, ok, err := loaded.HistoryEntry(1)
+	if err != nil || !ok || entry.Charm != "db/Backup" || entry.Args["name"] != "web" {
+		t.Errorf("HistoryEntry(1) = %+v, %v, %v, want db/Backup", entry, ok, err)
+	}
+}
+
+func TestStore_HistoryLimit(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < MaxHistory+5; i++ {
+		if _, err := store.AddHistory(HistoryEntry{Charm: "a"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := Load(store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := loaded.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != MaxHistory || entries[0].ID != MaxHistory+5 {
+		t.Errorf("History() has %d entries, newest %d, want %d, newest %d", len(entries), entries[0].ID, MaxHistory, MaxHistory+5)
+	}
+}
+
+func TestStore_PromptHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []string{"web-1", "web-2", "web-1"} {
+		if err := store.AddPromptHistory("host", value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.AddPromptHistory("user", "root"); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := loaded.PromptHistory("host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"web-1", "web-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PromptHistory() = %v, want %v", got, want)
+	}
+}
+
+func TestStore_HistoryLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.History(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process, e.g. a background job, holds the lock while it appends its entry
+	unlock, err := store.lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	added := make(chan HistoryEntry)
+	go func() {
+		entry, err := store.AddHistory(HistoryEntry{Charm: "menu"})
+		if err != nil {
+			t.Error(err)
+		}
+		added <- entry
+	}()
+
+	other.history = []HistoryEntry{{ID: 1, Charm: "job"}}
+	if err := other.writeHistory(other.historyPath()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case entry := <-added:
+		t.Fatalf("AddHistory() did not wait for the lock, added %+v", entry)
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+
+	if entry := <-added; entry.ID != 2 {
+		t.Errorf("AddHistory() assigned ID %d, want 2 after the entry of the other process", entry.ID)
+	}
+}
+
+func TestStore_PromptHistoryLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := store.lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	added := make(chan error)
+	go func() {
+		added <- store.AddPromptHistory("host", "web-1")
+	}()
+
+	// Another process writes its value while holding the lock
+	other, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.prompts = map[string][]string{"host": {"web-2"}}
+	data, err := json.Marshal(other.prompts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(other.promptsPath(), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-added:
+		t.Fatalf("AddPromptHistory() did not wait for the lock, returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+
+	if err := <-added; err != nil {
+		t.Fatal(err)
+	}
+	got, err := other.PromptHistory("host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"web-1", "web-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PromptHistory() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package theme
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is the semantic color palette used by the charm selector and all console widgets
+type Theme struct {
+	Name string
+
+	Primary   lipgloss.Color // Titles, prompts, the cursor and active elements
+	Secondary lipgloss.Color // Regular text
+	Muted     lipgloss.Color // Hints, placeholders and inactive elements
+	Accent    lipgloss.Color // Secondary highlights, e.g. the working directory
+
+	Border          lipgloss.Color
+	Selection       lipgloss.Color // Background of the selected item
+	SelectionText   lipgloss.Color // Foreground of the selected item
+	Hover           lipgloss.Color // Foreground of the item under the mouse
+	HoverBackground lipgloss.Color
+	Match           lipgloss.Color // Background of search matches
+
+	Warning lipgloss.Color
+	Error   lipgloss.Color
+	Success lipgloss.Color
+
+	TagText lipgloss.Color
+	Tags    []lipgloss.Color // Tag chip backgrounds, a tag always gets the same one
+
+	Gradient [2]lipgloss.Color // Start and end color of progress bars
+
+	// Markdown is the glamour style used for descriptions, e.g. "dark" or "light"
+	Markdown string
+}
+
+// Dark is the theme for terminals with a dark background
+var Dark = Theme{
+	Name:            "dark",
+	Primary:         "75",
+	Secondary:       "#ccc",
+	Muted:           "#666666",
+	Accent:          "202",
+	Border:          "241",
+	Selection:       "236",
+	SelectionText:   "75",
+	Hover:           "39",
+	HoverBackground: "236",
+	Match:           "237",
+	Warning:         "#FFAF00",
+	Error:           "#FF5F5F",
+	Success:         "#5FD75F",
+	TagText:         "230",
+	Tags:            []lipgloss.Color{"24", "29", "53", "58", "89", "94", "60", "23"},
+	Gradient:        [2]lipgloss.Color{"#5956e0", "#e86ef6"},
+	Markdown:        "dark",
+}
+
+// Light is the theme for terminals with a light background
+var Light = Theme{
+	Name:            "light",
+	Primary:         "25",
+	Secondary:       "#303030",
+	Muted:           "#808080",
+	Accent:          "166",
+	Border:          "248",
+	Selection:       "254",
+	SelectionText:   "25",
+	Hover:           "31",
+	HoverBackground: "254",
+	Match:           "229",
+	Warning:         "#AF5F00",
+	Error:           "#D70000",
+	Success:         "#008700",
+	TagText:         "231",
+	Tags:            []lipgloss.Color{"25", "29", "91", "94", "125", "130", "61", "31"},
+	Gradient:        [2]lipgloss.Color{"#3f3cbb", "#b42bc4"},
+	Markdown:        "light",
+}
+
+// HighContrast uses bright, fully saturated colors on a dark background
+var HighContrast = Theme{
+	Name:            "high-contrast",
+	Primary:         "#00FFFF",
+	Secondary:       "#FFFFFF",
+	Muted:           "#C0C0C0",
+	Accent:          "#FFFF00",
+	Border:          "#FFFFFF",
+	Selection:       "#FFFF00",
+	SelectionText:   "#000000",
+	Hover:           "#000000",
+	HoverBackground: "#00FFFF",
+	Match:           "#AF00AF",
+	Warning:         "#FFFF00",
+	Error:           "#FF0000",
+	Success:         "#00FF00",
+	TagText:         "#FFFFFF",
+	Tags:            []lipgloss.Color{"#0000AF", "#005F00", "#870000", "#5F00AF"},
+	Gradient:        [2]lipgloss.Color{"#00FFFF", "#FF00FF"},
+	Markdown:        "dark",
+}
+
+// Detect returns Dark or Light depending on the background color of the terminal
+func Detect() Theme {
+	if lipgloss.HasDarkBackground() {
+		return Dark
+	}
+	return Light
+}
+
+// ByName returns a built-in theme: auto, dark, light or high-contrast.
+// The auto theme is resolved with Detect.
+func ByName(name string) (Theme, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "auto":
+		return Detect(), true
+	case Dark.Name:
+		return Dark, true
+	case Light.Name:
+		return Light, true
+	case HighContrast.Name, "highcontrast":
+		return HighContrast, true
+	}
+	return Theme{}, false
+}
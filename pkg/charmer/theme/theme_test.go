@@ -0,0 +1,47 @@
+package theme
+
+import "testing"
+
+func TestByName(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantName string
+		wantOk   bool
+	}{
+		{name: "dark", wantName: "dark", wantOk: true},
+		{name: " Light ", wantName: "light", wantOk: true},
+		{name: "high-contrast", wantName: "high-contrast", wantOk: true},
+		{name: "HighContrast", wantName: "high-contrast", wantOk: true},
+		{name: "solarized", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ByName(tt.name)
+			if ok != tt.wantOk || got.Name != tt.wantName {
+				t.Errorf("ByName(%q) = %q, %v, want %q, %v", tt.name, got.Name, ok, tt.wantName, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestBuiltinThemesAreComplete(t *testing.T) {
+	for _, theme := range []Theme{Dark, Light, HighContrast} {
+		colors := map[string]string{
+			"Primary": string(theme.Primary), "Secondary": string(theme.Secondary), "Muted": string(theme.Muted),
+			"Accent": string(theme.Accent), "Border": string(theme.Border), "Selection": string(theme.Selection),
+			"SelectionText": string(theme.SelectionText), "Hover": string(theme.Hover),
+			"HoverBackground": string(theme.HoverBackground), "Match": string(theme.Match),
+			"Warning": string(theme.Warning), "Error": string(theme.Error), "Success": string(theme.Success),
+			"TagText": string(theme.TagText), "Markdown": theme.Markdown,
+		}
+		for field, value := range colors {
+			if value == "" {
+				t.Errorf("%s theme has no %s color", theme.Name, field)
+			}
+		}
+		if len(theme.Tags) == 0 {
+			t.Errorf("%s theme has no tag colors", theme.Name)
+		}
+	}
+}
@@ -1,9 +1,32 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/ImGajeed76/charmer/internal/generator"
+	"log"
+	"os"
 )
 
 func main() {
-	generator.Generate()
+	options := generator.DefaultOptions()
+
+	flag.BoolVar(&options.Check, "check", false, "exit with a non-zero status if the generated registry is out of date")
+	flag.StringVar(&options.CharmsDir, "charms", options.CharmsDir, "directory containing the charms")
+	flag.StringVar(&options.Output, "out", options.Output, "path of the generated registry file")
+	flag.StringVar(&options.Package, "pkg", options.Package, "package name of the generated registry")
+	flag.StringVar(&options.Variable, "var", options.Variable, "name of the generated registry variable")
+	flag.StringVar(&options.GroupsVariable, "groups-var", options.GroupsVariable, "name of the generated group metadata variable")
+	flag.StringVar(&options.ModuleRoot, "module", options.ModuleRoot, "directory containing go.mod")
+	flag.Parse()
+
+	err := generator.Generate(options)
+	if errors.Is(err, generator.ErrStaleRegistry) {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 }